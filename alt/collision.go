@@ -0,0 +1,73 @@
+package alt
+
+import (
+	"fmt"
+)
+
+// CollisionStrategy 不同路径的字段写入同一个字段时的处理方式
+// 例如 {"a.b": 1, "a": {"b": 2}} 中的两个字段都会写入 a.b
+type CollisionStrategy int
+
+const (
+	// CollisionLastWins 后写入的值覆盖之前的值, 根据 ErrorMode 处理 ErrColumnCollision(默认)
+	CollisionLastWins CollisionStrategy = iota
+	// CollisionFirstWins 保留第一个写入该字段的路径的值, 丢弃其他路径的值, 根据 ErrorMode 处理 ErrColumnCollision
+	CollisionFirstWins
+	// CollisionSuffix 后写入的路径使用添加了 _1, _2 后缀的字段名
+	CollisionSuffix
+	// CollisionEscape 使用 \ 转义字段名中的 Separator 和 \, 例如 a\.b, 只支持 StrSeparator, 仍然冲突时添加后缀
+	CollisionEscape
+)
+
+// SetCollision 设置字段冲突的处理方式
+// 写入的顺序与遍历的顺序一致, CollisionFirstWins, CollisionSuffix 的结果依赖 map 的遍历顺序, 需要固定的结果时使用 SetDeterministic
+func SetCollision(strategy CollisionStrategy) OptionFunc {
+	return func(c *dataEtl) {
+		c.collision = strategy
+	}
+}
+
+// appendKey 将字段名追加到 prefix, CollisionEscape 时转义字段名中的 Separator
+func (c *dataEtl) appendKey(prefix string, key interface{}) string {
+	if c.collision == CollisionEscape {
+		if sep, ok := c.separator.(StrSeparator); ok {
+			return EscapeSeparator(sep).AppendToPrefix(prefix, key)
+		}
+	}
+	return c.separator.AppendToPrefix(prefix, key)
+}
+
+// resolveCollision 处理字段冲突, 返回实际写入的字段名, 返回空字符串时丢弃该值
+func (c *dataEtl) resolveCollision(target string, prev, path []string, v interface{}, depth int) (string, error) {
+	switch c.collision {
+	case CollisionSuffix, CollisionEscape:
+		return c.suffix(target, prev, path), nil
+	}
+	err := c.report(&ParseError{
+		Path:  target,
+		Kind:  kindOf(v),
+		Depth: depth,
+		Err:   fmt.Errorf("%w: %s and %s are both written to %s", ErrColumnCollision, formatPath(prev), formatPath(path), target),
+	})
+	if err != nil || c.collision == CollisionFirstWins {
+		return "", err
+	}
+	return target, nil
+}
+
+// suffix 为冲突的路径分配添加了后缀的字段名, 同一个路径总是使用相同的字段名
+func (c *dataEtl) suffix(target string, prev, path []string) string {
+	source := formatPath(path)
+	if s, ok := c.state.suffixes[source]; ok {
+		return s
+	}
+	for i := 1; ; i++ {
+		s := fmt.Sprintf("%s_%d", target, i)
+		if _, ok := c.state.columns[s]; !ok {
+			c.logger.Warn(fmt.Sprintf("column collision: %s and %s are both written to %s, use %s", formatPath(prev), source, target, s))
+			c.state.columns[s] = path
+			c.state.suffixes[source] = s
+			return s
+		}
+	}
+}
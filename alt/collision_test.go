@@ -0,0 +1,120 @@
+package alt
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_dataEtl_collision(t *testing.T) {
+	var data = map[string]interface{}{
+		"a.b": 1,
+		"a":   map[string]interface{}{"b": 2},
+		`c\d`: 3,
+	}
+	tests := []struct {
+		name       string
+		opt        []OptionFunc
+		wantResult matrixKvPairs
+		wantErr    error
+	}{
+		{
+			name: "escape",
+			opt:  []OptionFunc{SetCollision(CollisionEscape)},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "a.b", Value: 2}, {Key: `a\.b`, Value: 1}, {Key: `c\\d`, Value: 3}},
+			},
+		},
+		{
+			name:    "last_wins",
+			opt:     []OptionFunc{SetErrorMode(ErrorModeFailFast)},
+			wantErr: ErrColumnCollision,
+		},
+		{
+			name:    "first_wins",
+			opt:     []OptionFunc{SetCollision(CollisionFirstWins), SetErrorMode(ErrorModeFailFast)},
+			wantErr: ErrColumnCollision,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := newTestParser(tt.opt...)
+			gotResult, err := parser.ParseE(data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(covertHelper(gotResult), tt.wantResult) {
+				t.Errorf("ParseE() = %v, want %v", covertHelper(gotResult), tt.wantResult)
+			}
+		})
+	}
+}
+
+func Test_dataEtl_collision_wins(t *testing.T) {
+	// 基础类型的字段先于嵌套的字段填充
+	var primitive = map[string]interface{}{"a.b": 1, "a": map[string]interface{}{"b": 2}}
+	// 都是嵌套的字段时按照遍历顺序填充, 固定顺序时 a 先于 a.b
+	var nested = map[string]interface{}{"a": map[string]interface{}{"b.c": 1}, "a.b": map[string]interface{}{"c": 2}}
+	tests := []struct {
+		name       string
+		data       interface{}
+		opt        []OptionFunc
+		wantResult matrixKvPairs
+	}{
+		{
+			name:       "last_wins",
+			data:       primitive,
+			opt:        []OptionFunc{SetCollision(CollisionLastWins)},
+			wantResult: matrixKvPairs{[]pair{{Key: "a.b", Value: 2}}},
+		},
+		{
+			name:       "first_wins",
+			data:       primitive,
+			opt:        []OptionFunc{SetCollision(CollisionFirstWins)},
+			wantResult: matrixKvPairs{[]pair{{Key: "a.b", Value: 1}}},
+		},
+		{
+			name:       "nested_last_wins",
+			data:       nested,
+			opt:        []OptionFunc{SetCollision(CollisionLastWins), SetDeterministic(true)},
+			wantResult: matrixKvPairs{[]pair{{Key: "a.b.c", Value: 2}}},
+		},
+		{
+			name:       "nested_first_wins",
+			data:       nested,
+			opt:        []OptionFunc{SetCollision(CollisionFirstWins), SetDeterministic(true)},
+			wantResult: matrixKvPairs{[]pair{{Key: "a.b.c", Value: 1}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkParse(t, newTestParser(append([]OptionFunc{SetErrorMode(ErrorModeSkip)}, tt.opt...)...), tt.data, tt.wantResult)
+		})
+	}
+}
+
+func Test_dataEtl_collision_suffix(t *testing.T) {
+	parser := newTestParser(
+		SetCollision(CollisionSuffix),
+		SetErrorMode(ErrorModeFailFast),
+	)
+	gotResult, err := parser.ParseE([]interface{}{
+		map[string]interface{}{"a.b": 1, "a": map[string]interface{}{"b": 2}},
+		map[string]interface{}{"a.b": 1, "a": map[string]interface{}{"b": 2}},
+	})
+	if err != nil {
+		t.Fatalf("ParseE() error = %v", err)
+	}
+	if len(gotResult) != 2 {
+		t.Fatalf("ParseE() = %v, want 2 rows", gotResult)
+	}
+	// 同一个路径在每一行都使用相同的字段名
+	for _, row := range gotResult {
+		if !reflect.DeepEqual(row, gotResult[0]) {
+			t.Errorf("ParseE() = %v, rows are different", gotResult)
+		}
+		if len(row) != 2 || row["a.b_1"] == nil || row["a.b"] == nil || row["a.b"] == row["a.b_1"] {
+			t.Errorf("ParseE() = %v, want a.b and a.b_1", row)
+		}
+	}
+}
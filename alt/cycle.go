@@ -0,0 +1,131 @@
+package alt
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// CyclePolicy 遇到循环引用时的处理方式
+type CyclePolicy int
+
+const (
+	// CycleCut 丢弃形成循环的字段, 根据 ErrorMode 处理 ErrCycle(默认)
+	CycleCut CyclePolicy = iota
+	// CycleError 返回错误, 拒绝整个文档
+	CycleError
+	// CycleRef 形成循环的字段输出为引用标记, 例如 $ref:data.parent, 根对象为 $ref:$
+	CycleRef
+)
+
+// RefPrefix 引用标记的前缀
+const RefPrefix = "$ref:"
+
+// SetCyclePolicy 设置遇到循环引用时的处理方式
+// 指针, map 和 slice 已经在当前路径上出现过时认为形成了循环, 不同路径共享的对象不是循环
+func SetCyclePolicy(policy CyclePolicy) OptionFunc {
+	return func(c *dataEtl) {
+		c.cyclePolicy = policy
+	}
+}
+
+// reference 可能形成循环的引用
+type reference struct {
+	ptr uintptr
+	len int
+	typ reflect.Type
+}
+
+// referenceOf 返回指针, map 和 slice 的引用
+func referenceOf(data interface{}) (ref reference, ok bool) {
+	if data == nil {
+		return ref, false
+	}
+	rv := reflect.ValueOf(data)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map:
+		if rv.IsNil() {
+			return ref, false
+		}
+		return reference{ptr: rv.Pointer(), typ: rv.Type()}, true
+	case reflect.Slice:
+		if rv.Len() == 0 {
+			return ref, false
+		}
+		return reference{ptr: rv.Pointer(), len: rv.Len(), typ: rv.Type()}, true
+	}
+	return ref, false
+}
+
+// visit 记录当前路径上正在展开的引用, 引用已经在当前路径上时返回引用第一次出现的字段
+func (c *dataEtl) visit(data interface{}, prefix string) (ref *reference, at string, cycle bool) {
+	r, ok := referenceOf(data)
+	if !ok {
+		return nil, "", false
+	}
+	return c.visitRef(r, prefix)
+}
+
+// visitRef 与 visit 相同, 用于无法读取值的字段, 例如未导出的匿名嵌入的指针
+func (c *dataEtl) visitRef(r reference, prefix string) (ref *reference, at string, cycle bool) {
+	if at, cycle = c.state.visiting[r]; cycle {
+		return nil, at, true
+	}
+	c.state.visiting[r] = prefix
+	return &r, "", false
+}
+
+// leave 引用展开结束
+func (c *dataEtl) leave(ref *reference) {
+	delete(c.state.visiting, *ref)
+}
+
+// pause 输出行之后展开的是其他字段, 输出期间引用不在当前路径上
+func (c *dataEtl) pause(ref *reference, prefix string, emit emitFunc) emitFunc {
+	return func(row map[string]interface{}) error {
+		delete(c.state.visiting, *ref)
+		err := emit(row)
+		c.state.visiting[*ref] = prefix
+		return err
+	}
+}
+
+// cycleValue 根据 CyclePolicy 处理循环引用, 返回需要填充到字段的引用标记
+func (c *dataEtl) cycleValue(data interface{}, prefix string, at string, depth int) (interface{}, error) {
+	if at == "" {
+		at = "$"
+	}
+	err := &ParseError{Path: prefix, Kind: reflect.TypeOf(data).Kind(), Depth: depth, Err: fmt.Errorf("%w to %s", ErrCycle, at)}
+	switch c.cyclePolicy {
+	case CycleError:
+		c.logger.Warn(err.Error())
+		return nil, err
+	case CycleRef:
+		c.logger.Debug(err.Error())
+		return RefPrefix + at, nil
+	}
+	return nil, c.report(err)
+}
+
+// parseCycle 处理循环引用, 不再继续展开
+func (c *dataEtl) parseCycle(
+	data interface{},
+	prefix string,
+	path []string,
+	at string,
+	currentMap map[string]interface{},
+	depth int,
+	emit emitFunc,
+) error {
+	v, err := c.cycleValue(data, prefix, at, depth)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return emit(currentMap)
+	}
+	tmp := cpm(currentMap)
+	if err := c.put(tmp, prefix, path, v, depth); err != nil {
+		return err
+	}
+	return emit(tmp)
+}
@@ -0,0 +1,187 @@
+package alt
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+type node struct {
+	Name string
+	Next *node
+}
+
+// Chain 匿名嵌入自身类型的指针
+type Chain struct {
+	*Chain
+	X int
+}
+
+type chain struct {
+	*chain
+	X int
+}
+
+func Test_dataEtl_cycle(t *testing.T) {
+	var self = map[string]interface{}{"name": "a"}
+	self["self"] = self
+
+	var a, b = &node{Name: "a"}, &node{Name: "b"}
+	a.Next, b.Next = b, a
+
+	var list = []interface{}{"x", nil}
+	list[1] = list
+
+	var shared = map[string]interface{}{"x": 1}
+
+	var embedded = &Chain{X: 1}
+	embedded.Chain = embedded
+	var unexported = &chain{X: 1}
+	unexported.chain = unexported
+	// 嵌入的指针指向另一个对象, 在第二层形成循环
+	var inner = &Chain{X: 2}
+	inner.Chain = inner
+	var outer = Chain{Chain: inner, X: 1}
+
+	tests := []struct {
+		name       string
+		data       interface{}
+		opt        []OptionFunc
+		wantResult matrixKvPairs
+		wantErr    error
+	}{
+		{
+			name: "map_cut",
+			data: self,
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "name", Value: "a"}},
+			},
+		},
+		{
+			name: "map_ref",
+			data: self,
+			opt:  []OptionFunc{SetCyclePolicy(CycleRef)},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "name", Value: "a"}, {Key: "self", Value: "$ref:$"}},
+			},
+		},
+		{
+			name:    "map_error",
+			data:    self,
+			opt:     []OptionFunc{SetCyclePolicy(CycleError)},
+			wantErr: ErrCycle,
+		},
+		{
+			name:    "map_collect",
+			data:    self,
+			opt:     []OptionFunc{SetErrorMode(ErrorModeCollect)},
+			wantErr: ErrCycle,
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "name", Value: "a"}},
+			},
+		},
+		{
+			name: "pointer_ref",
+			data: a,
+			opt:  []OptionFunc{SetCyclePolicy(CycleRef)},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "Name", Value: "a"}, {Key: "Next.Name", Value: "b"}, {Key: "Next.Next", Value: "$ref:$"}},
+			},
+		},
+		{
+			name: "slice_ref",
+			data: map[string]interface{}{"list": list},
+			opt:  []OptionFunc{SetCyclePolicy(CycleRef)},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "list", Value: "$ref:list"}},
+				[]pair{{Key: "list", Value: "x"}},
+			},
+		},
+		{
+			name: "embedded_cut",
+			data: embedded,
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "X", Value: 1}},
+			},
+		},
+		{
+			name: "embedded_ref",
+			data: embedded,
+			opt:  []OptionFunc{SetCyclePolicy(CycleRef)},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "Chain", Value: "$ref:$"}, {Key: "X", Value: 1}},
+			},
+		},
+		{
+			name:    "embedded_error",
+			data:    embedded,
+			opt:     []OptionFunc{SetCyclePolicy(CycleError)},
+			wantErr: ErrCycle,
+		},
+		{
+			name: "embedded_unexported",
+			data: unexported,
+			opt:  []OptionFunc{SetCyclePolicy(CycleRef)},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "X", Value: 1}, {Key: "chain", Value: "$ref:$"}},
+			},
+		},
+		{
+			name: "embedded_inner",
+			data: outer,
+			opt:  []OptionFunc{SetCyclePolicy(CycleRef)},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "Chain", Value: "$ref:$"}, {Key: "X", Value: 1}},
+			},
+		},
+		{
+			name: "shared",
+			data: map[string]interface{}{"a": shared, "b": shared, "c": []interface{}{shared, map[string]interface{}{"x": 2}}},
+			opt:  []OptionFunc{SetCyclePolicy(CycleError)},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "a.x", Value: 1}, {Key: "b.x", Value: 1}, {Key: "c.x", Value: 1}},
+				[]pair{{Key: "a.x", Value: 1}, {Key: "b.x", Value: 1}, {Key: "c.x", Value: 2}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := newTestParser(tt.opt...)
+			gotResult, err := parser.ParseE(tt.data)
+			if errs, ok := err.(ParseErrors); ok {
+				err = errs[0]
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(covertHelper(gotResult), tt.wantResult) {
+				t.Errorf("ParseE() = %v, want %v", covertHelper(gotResult), tt.wantResult)
+			}
+			if err != nil {
+				return
+			}
+			if got := parser.(*dataEtl).fork().countDocument(tt.data, nil); got != uint64(len(tt.wantResult)) {
+				t.Errorf("countDocument() = %v, want %v", got, len(tt.wantResult))
+			}
+		})
+	}
+}
+
+func Test_dataEtl_ParseTables_cycle(t *testing.T) {
+	var self = map[string]interface{}{"name": "a"}
+	self["self"] = self
+	self["list"] = []interface{}{self}
+	parser := NewRelationalParser(SetLogger(NewStdLogger(LevelError, os.Stdout)), SetCyclePolicy(CycleRef))
+	tables, err := parser.ParseTables(self)
+	if err != nil {
+		t.Fatalf("ParseTables() error = %v", err)
+	}
+	want := map[string][]map[string]interface{}{
+		RootTable: {{"_id": int64(1), "name": "a", "self": "$ref:$"}},
+		"list":    {{"_id": int64(2), "_parent_id": int64(1), "_value": "$ref:$"}},
+	}
+	if !reflect.DeepEqual(tables, want) {
+		t.Errorf("ParseTables() = %v, want %v", tables, want)
+	}
+}
@@ -0,0 +1,291 @@
+package alt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Dialect 生成 DDL 的数据库
+type Dialect int
+
+const (
+	DialectClickHouse Dialect = iota
+	DialectDoris
+	DialectMySQL
+)
+
+func (d Dialect) String() string {
+	switch d {
+	case DialectClickHouse:
+		return "clickhouse"
+	case DialectDoris:
+		return "doris"
+	case DialectMySQL:
+		return "mysql"
+	}
+	return fmt.Sprintf("Dialect(%d)", int(d))
+}
+
+// DDLOptions 生成 DDL 的参数
+type DDLOptions struct {
+	// Table 表名, 可以包含库名, 例如 db.events
+	Table string
+	// OrderBy ClickHouse 的 ORDER BY 以及 Doris 的 DUPLICATE KEY, 为空时 ClickHouse 使用 tuple(),
+	// Doris 使用第一个可以作为 key 的字段(不是 float), 优先使用不能为空的字段
+	// ClickHouse 的排序字段不会使用 Nullable, Doris 的 key 字段会排在其他字段之前, MySQL 忽略
+	OrderBy []string
+	// Engine ClickHouse 的表引擎, 默认为 MergeTree()
+	Engine string
+	// Buckets Doris 的分桶数, 默认为 10
+	Buckets int
+	// Properties Doris 的 PROPERTIES, 例如 {"replication_num": "1"}
+	Properties map[string]string
+}
+
+// CreateTable 根据推断出的表结构生成建表语句
+func CreateTable(schema *Schema, dialect Dialect, opt DDLOptions) (string, error) {
+	columns, keys, err := ddlColumns(schema, dialect, opt)
+	if err != nil {
+		return "", err
+	}
+	var (
+		isKey = make(map[string]struct{}, len(keys))
+		order = make([]string, 0, len(keys))
+	)
+	for _, key := range keys {
+		isKey[key] = struct{}{}
+		order = append(order, quoteIdent(key))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE IF NOT EXISTS %s (\n", quoteTable(opt.Table))
+	for i, col := range columns {
+		_, key := isKey[col.Name]
+		fmt.Fprintf(&b, "    %s %s", quoteIdent(col.Name), columnType(col, dialect, key, false))
+		if i < len(columns)-1 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteByte(')')
+
+	switch dialect {
+	case DialectClickHouse:
+		engine := opt.Engine
+		if engine == "" {
+			engine = "MergeTree()"
+		}
+		fmt.Fprintf(&b, "\nENGINE = %s\n", engine)
+		if len(order) == 0 {
+			b.WriteString("ORDER BY tuple()")
+		} else {
+			fmt.Fprintf(&b, "ORDER BY (%s)", strings.Join(order, ", "))
+		}
+	case DialectDoris:
+		buckets := opt.Buckets
+		if buckets <= 0 {
+			buckets = 10
+		}
+		fmt.Fprintf(&b, "\nDUPLICATE KEY(%s)\nDISTRIBUTED BY HASH(%s) BUCKETS %d", strings.Join(order, ", "), order[0], buckets)
+		if len(opt.Properties) > 0 {
+			var props = make([]string, 0, len(opt.Properties))
+			for k, v := range opt.Properties {
+				props = append(props, fmt.Sprintf("    %q = %q", k, v))
+			}
+			sort.Strings(props)
+			fmt.Fprintf(&b, "\nPROPERTIES (\n%s\n)", strings.Join(props, ",\n"))
+		}
+	case DialectMySQL:
+		b.WriteString(" ENGINE=InnoDB DEFAULT CHARSET=utf8mb4")
+	}
+	return b.String(), nil
+}
+
+// AlterTable 生成新的表结构中新增字段的 ALTER TABLE ADD COLUMN 语句, 已有的行没有新增的字段, 所以新增的字段总是可以为空
+// 不会处理删除的字段以及类型的变化
+func AlterTable(prev, next *Schema, dialect Dialect, opt DDLOptions) ([]string, error) {
+	if next == nil {
+		return nil, fmt.Errorf("ddl: schema is nil")
+	}
+	if opt.Table == "" {
+		return nil, fmt.Errorf("ddl: table name is empty")
+	}
+	if dialect < DialectClickHouse || dialect > DialectMySQL {
+		return nil, fmt.Errorf("ddl: unsupported dialect %v", dialect)
+	}
+	var stmts []string
+	for _, col := range next.Columns {
+		if prev != nil {
+			if _, ok := prev.Column(col.Name); ok {
+				continue
+			}
+		}
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s",
+			quoteTable(opt.Table), quoteIdent(col.Name), columnType(col, dialect, false, true)))
+	}
+	return stmts, nil
+}
+
+// ddlColumns 返回建表的字段以及去重之后的排序字段, Doris 的 key 字段排在其他字段之前
+func ddlColumns(schema *Schema, dialect Dialect, opt DDLOptions) (columns []ColumnSchema, keys []string, err error) {
+	if schema == nil || len(schema.Columns) == 0 {
+		return nil, nil, fmt.Errorf("ddl: schema has no columns")
+	}
+	if dialect < DialectClickHouse || dialect > DialectMySQL {
+		return nil, nil, fmt.Errorf("ddl: unsupported dialect %v", dialect)
+	}
+	if opt.Table == "" {
+		return nil, nil, fmt.Errorf("ddl: table name is empty")
+	}
+	orderBy := opt.OrderBy
+	if dialect == DialectDoris && len(orderBy) == 0 {
+		key, ok := dorisDefaultKey(schema)
+		if !ok {
+			return nil, nil, fmt.Errorf("ddl: no column can be a doris key, all columns are float")
+		}
+		orderBy = []string{key}
+	}
+	columns = make([]ColumnSchema, 0, len(schema.Columns))
+	if dialect == DialectMySQL {
+		return append(columns, schema.Columns...), nil, nil
+	}
+	var isKey = make(map[string]struct{}, len(orderBy))
+	for _, name := range orderBy {
+		col, ok := schema.Column(name)
+		if !ok {
+			return nil, nil, fmt.Errorf("ddl: order by column %s not found", name)
+		}
+		if dialect == DialectDoris && col.Type == TypeFloat {
+			return nil, nil, fmt.Errorf("ddl: float column %s can not be a doris key", name)
+		}
+		if _, ok := isKey[name]; !ok {
+			isKey[name] = struct{}{}
+			keys = append(keys, name)
+			if dialect == DialectDoris {
+				columns = append(columns, col)
+			}
+		}
+	}
+	for _, col := range schema.Columns {
+		if _, ok := isKey[col.Name]; !ok || dialect != DialectDoris {
+			columns = append(columns, col)
+		}
+	}
+	return columns, keys, nil
+}
+
+// dorisDefaultKey 返回第一个可以作为 Doris key 的字段, 优先使用不能为空的字段
+func dorisDefaultKey(schema *Schema) (string, bool) {
+	var nullable = -1
+	for i, col := range schema.Columns {
+		if col.Type == TypeFloat {
+			continue
+		}
+		if !col.Nullable {
+			return col.Name, true
+		}
+		if nullable < 0 {
+			nullable = i
+		}
+	}
+	if nullable < 0 {
+		return "", false
+	}
+	return schema.Columns[nullable].Name, true
+}
+
+// columnType 返回字段在数据库中的类型, key 为排序字段, added 为新增的字段
+func columnType(col ColumnSchema, dialect Dialect, key, added bool) string {
+	nullable := col.Nullable || added
+	switch dialect {
+	case DialectClickHouse:
+		var t string
+		switch col.Type {
+		case TypeBool:
+			t = "Bool"
+		case TypeInt:
+			t = "Int64"
+		case TypeFloat:
+			t = "Float64"
+		case TypeTime:
+			t = "DateTime64(3)"
+		default:
+			t = "String"
+		}
+		if nullable && !key {
+			return "Nullable(" + t + ")"
+		}
+		return t
+	case DialectDoris:
+		var t string
+		switch col.Type {
+		case TypeBool:
+			t = "BOOLEAN"
+		case TypeInt:
+			t = "BIGINT"
+		case TypeFloat:
+			t = "DOUBLE"
+		case TypeTime:
+			t = "DATETIME(3)"
+		default:
+			// VARCHAR 的长度为字节数, key 字段不能使用 STRING
+			n := col.MaxLen * 4
+			if n < 255 {
+				n = 255
+			}
+			switch {
+			case n <= 65533:
+				t = fmt.Sprintf("VARCHAR(%d)", n)
+			case key:
+				t = "VARCHAR(65533)"
+			default:
+				t = "STRING"
+			}
+		}
+		return t + nullSuffix(nullable)
+	default:
+		var t string
+		switch col.Type {
+		case TypeBool:
+			t = "BOOLEAN"
+		case TypeInt:
+			t = "BIGINT"
+		case TypeFloat:
+			t = "DOUBLE"
+		case TypeTime:
+			t = "DATETIME(3)"
+		default:
+			// TEXT 最多 65535 字节, 即 16383 个 utf8mb4 字符
+			switch {
+			case col.MaxLen <= 255:
+				t = "VARCHAR(255)"
+			case col.MaxLen <= 16383:
+				t = "TEXT"
+			default:
+				t = "MEDIUMTEXT"
+			}
+		}
+		return t + nullSuffix(nullable)
+	}
+}
+
+func nullSuffix(nullable bool) string {
+	if nullable {
+		return " NULL"
+	}
+	return " NOT NULL"
+}
+
+// quoteIdent 使用反引号引用字段名
+func quoteIdent(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+// quoteTable 引用表名, 库名和表名使用 . 分隔
+func quoteTable(table string) string {
+	parts := strings.Split(table, ".")
+	for i, p := range parts {
+		parts[i] = quoteIdent(p)
+	}
+	return strings.Join(parts, ".")
+}
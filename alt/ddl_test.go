@@ -0,0 +1,210 @@
+package alt
+
+import (
+	"reflect"
+	"testing"
+)
+
+var ddlSchema = &Schema{Rows: 4, Columns: []ColumnSchema{
+	{Name: "at", Type: TypeTime, Count: 4},
+	{Name: "data.age", Type: TypeInt, Nullable: true, Count: 3},
+	{Name: "data.score", Type: TypeFloat, Count: 4},
+	{Name: "name", Type: TypeString, Count: 4, MaxLen: 100},
+	{Name: "ok", Type: TypeBool, Nullable: true, Count: 1},
+	{Name: "order", Type: TypeString, Nullable: true, Count: 1, MaxLen: 20000},
+}}
+
+func TestCreateTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		schema  *Schema
+		opt     DDLOptions
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "clickhouse",
+			dialect: DialectClickHouse,
+			opt:     DDLOptions{Table: "db.events", OrderBy: []string{"name", "data.age", "name"}},
+			want: "CREATE TABLE IF NOT EXISTS `db`.`events` (\n" +
+				"    `at` DateTime64(3),\n" +
+				"    `data.age` Int64,\n" +
+				"    `data.score` Float64,\n" +
+				"    `name` String,\n" +
+				"    `ok` Nullable(Bool),\n" +
+				"    `order` Nullable(String)\n" +
+				")\n" +
+				"ENGINE = MergeTree()\n" +
+				"ORDER BY (`name`, `data.age`)",
+		},
+		{
+			name:    "clickhouse_tuple",
+			dialect: DialectClickHouse,
+			opt:     DDLOptions{Table: "events", Engine: "ReplacingMergeTree()"},
+			want: "CREATE TABLE IF NOT EXISTS `events` (\n" +
+				"    `at` DateTime64(3),\n" +
+				"    `data.age` Nullable(Int64),\n" +
+				"    `data.score` Float64,\n" +
+				"    `name` String,\n" +
+				"    `ok` Nullable(Bool),\n" +
+				"    `order` Nullable(String)\n" +
+				")\n" +
+				"ENGINE = ReplacingMergeTree()\n" +
+				"ORDER BY tuple()",
+		},
+		{
+			name:    "doris",
+			dialect: DialectDoris,
+			opt:     DDLOptions{Table: "events", OrderBy: []string{"name", "at"}, Properties: map[string]string{"replication_num": "1"}},
+			want: "CREATE TABLE IF NOT EXISTS `events` (\n" +
+				"    `name` VARCHAR(400) NOT NULL,\n" +
+				"    `at` DATETIME(3) NOT NULL,\n" +
+				"    `data.age` BIGINT NULL,\n" +
+				"    `data.score` DOUBLE NOT NULL,\n" +
+				"    `ok` BOOLEAN NULL,\n" +
+				"    `order` STRING NULL\n" +
+				")\n" +
+				"DUPLICATE KEY(`name`, `at`)\n" +
+				"DISTRIBUTED BY HASH(`name`) BUCKETS 10\n" +
+				"PROPERTIES (\n" +
+				"    \"replication_num\" = \"1\"\n" +
+				")",
+		},
+		{
+			name:    "doris_default_key",
+			dialect: DialectDoris,
+			opt:     DDLOptions{Table: "events", Buckets: 3},
+			want: "CREATE TABLE IF NOT EXISTS `events` (\n" +
+				"    `at` DATETIME(3) NOT NULL,\n" +
+				"    `data.age` BIGINT NULL,\n" +
+				"    `data.score` DOUBLE NOT NULL,\n" +
+				"    `name` VARCHAR(400) NOT NULL,\n" +
+				"    `ok` BOOLEAN NULL,\n" +
+				"    `order` STRING NULL\n" +
+				")\n" +
+				"DUPLICATE KEY(`at`)\n" +
+				"DISTRIBUTED BY HASH(`at`) BUCKETS 3",
+		},
+		{
+			// {"a":1.5,"b":"x"} 第一个字段是 float, 不能作为 key
+			name:    "doris_skip_float",
+			dialect: DialectDoris,
+			schema: &Schema{Rows: 2, Columns: []ColumnSchema{
+				{Name: "a", Type: TypeFloat, Count: 2},
+				{Name: "b", Type: TypeString, Count: 2, MaxLen: 1},
+			}},
+			opt: DDLOptions{Table: "events"},
+			want: "CREATE TABLE IF NOT EXISTS `events` (\n" +
+				"    `b` VARCHAR(255) NOT NULL,\n" +
+				"    `a` DOUBLE NOT NULL\n" +
+				")\n" +
+				"DUPLICATE KEY(`b`)\n" +
+				"DISTRIBUTED BY HASH(`b`) BUCKETS 10",
+		},
+		{
+			name:    "doris_prefer_not_null",
+			dialect: DialectDoris,
+			schema: &Schema{Rows: 2, Columns: []ColumnSchema{
+				{Name: "a", Type: TypeInt, Nullable: true, Count: 1},
+				{Name: "b", Type: TypeFloat, Count: 2},
+				{Name: "c", Type: TypeInt, Count: 2},
+			}},
+			opt: DDLOptions{Table: "events"},
+			want: "CREATE TABLE IF NOT EXISTS `events` (\n" +
+				"    `c` BIGINT NOT NULL,\n" +
+				"    `a` BIGINT NULL,\n" +
+				"    `b` DOUBLE NOT NULL\n" +
+				")\n" +
+				"DUPLICATE KEY(`c`)\n" +
+				"DISTRIBUTED BY HASH(`c`) BUCKETS 10",
+		},
+		{
+			name:    "mysql",
+			dialect: DialectMySQL,
+			opt:     DDLOptions{Table: "events", OrderBy: []string{"name"}},
+			want: "CREATE TABLE IF NOT EXISTS `events` (\n" +
+				"    `at` DATETIME(3) NOT NULL,\n" +
+				"    `data.age` BIGINT NULL,\n" +
+				"    `data.score` DOUBLE NOT NULL,\n" +
+				"    `name` VARCHAR(255) NOT NULL,\n" +
+				"    `ok` BOOLEAN NULL,\n" +
+				"    `order` MEDIUMTEXT NULL\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		},
+		{name: "doris_all_float", dialect: DialectDoris, schema: &Schema{Rows: 1, Columns: []ColumnSchema{{Name: "a", Type: TypeFloat, Count: 1}}}, opt: DDLOptions{Table: "events"}, wantErr: true},
+		{name: "missing_order_by", dialect: DialectClickHouse, opt: DDLOptions{Table: "events", OrderBy: []string{"missing"}}, wantErr: true},
+		{name: "doris_float_key", dialect: DialectDoris, opt: DDLOptions{Table: "events", OrderBy: []string{"data.score"}}, wantErr: true},
+		{name: "empty_table", dialect: DialectMySQL, wantErr: true},
+		{name: "unsupported_dialect", dialect: Dialect(9), opt: DDLOptions{Table: "events"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := tt.schema
+			if schema == nil {
+				schema = ddlSchema
+			}
+			got, err := CreateTable(schema, tt.dialect, tt.opt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateTable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CreateTable() = \n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlterTable(t *testing.T) {
+	prev := &Schema{Rows: 1, Columns: []ColumnSchema{{Name: "at", Type: TypeTime}, {Name: "name", Type: TypeString}}}
+	tests := []struct {
+		name    string
+		dialect Dialect
+		want    []string
+	}{
+		{
+			name:    "clickhouse",
+			dialect: DialectClickHouse,
+			want: []string{
+				"ALTER TABLE `events` ADD COLUMN `data.age` Nullable(Int64)",
+				"ALTER TABLE `events` ADD COLUMN `data.score` Nullable(Float64)",
+				"ALTER TABLE `events` ADD COLUMN `ok` Nullable(Bool)",
+				"ALTER TABLE `events` ADD COLUMN `order` Nullable(String)",
+			},
+		},
+		{
+			name:    "doris",
+			dialect: DialectDoris,
+			want: []string{
+				"ALTER TABLE `events` ADD COLUMN `data.age` BIGINT NULL",
+				"ALTER TABLE `events` ADD COLUMN `data.score` DOUBLE NULL",
+				"ALTER TABLE `events` ADD COLUMN `ok` BOOLEAN NULL",
+				"ALTER TABLE `events` ADD COLUMN `order` STRING NULL",
+			},
+		},
+		{
+			name:    "mysql",
+			dialect: DialectMySQL,
+			want: []string{
+				"ALTER TABLE `events` ADD COLUMN `data.age` BIGINT NULL",
+				"ALTER TABLE `events` ADD COLUMN `data.score` DOUBLE NULL",
+				"ALTER TABLE `events` ADD COLUMN `ok` BOOLEAN NULL",
+				"ALTER TABLE `events` ADD COLUMN `order` MEDIUMTEXT NULL",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AlterTable(prev, ddlSchema, tt.dialect, DDLOptions{Table: "events"})
+			if err != nil {
+				t.Fatalf("AlterTable() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AlterTable() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got, err := AlterTable(ddlSchema, ddlSchema, DialectMySQL, DDLOptions{Table: "events"}); err != nil || len(got) != 0 {
+		t.Errorf("AlterTable() = %q, error = %v, want nothing", got, err)
+	}
+}
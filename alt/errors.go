@@ -0,0 +1,73 @@
+package alt
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrorMode 解析过程中遇到异常数据时的处理方式
+type ErrorMode int
+
+const (
+	// ErrorModeSkip 记录日志并跳过异常字段(默认)
+	ErrorModeSkip ErrorMode = iota
+	// ErrorModeFailFast 遇到第一个异常立即返回错误
+	ErrorModeFailFast
+	// ErrorModeCollect 跳过异常字段, 解析结束后返回所有的错误
+	ErrorModeCollect
+)
+
+var (
+	ErrUnsupportedKind = errors.New("unsupported kind")
+	ErrMaxDepth        = errors.New("exceed max depth")
+	ErrMaxRows         = errors.New("exceed max rows")
+	ErrMaxArrayLen     = errors.New("exceed max array length")
+	ErrMaxIndex        = errors.New("exceed max index")
+	ErrCycle           = errors.New("cycle reference")
+	ErrColumnCollision = errors.New("column collision")
+	ErrInvalidKey      = errors.New("invalid key")
+	ErrAmbiguousList   = errors.New("ambiguous list")
+
+	// ErrStop ParseEach 的回调函数返回 ErrStop 时提前结束解析, 不作为错误返回
+	ErrStop = errors.New("stop parse")
+
+	// ErrDrop TransformFunc 返回 ErrDrop 时丢弃该字段
+	ErrDrop = errors.New("drop value")
+)
+
+// ParseError 带有异常字段路径, 类型和深度的错误
+type ParseError struct {
+	Path  string
+	Kind  reflect.Kind
+	Depth int
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("path %q: %v (kind:%v, depth:%d)", e.Path, e.Err, e.Kind, e.Depth)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// kindOf 返回值的类型, nil 返回 reflect.Invalid
+func kindOf(v interface{}) reflect.Kind {
+	if v == nil {
+		return reflect.Invalid
+	}
+	return reflect.TypeOf(v).Kind()
+}
+
+// ParseErrors ErrorModeCollect 模式下收集到的所有错误
+type ParseErrors []*ParseError
+
+func (e ParseErrors) Error() string {
+	msg := make([]string, 0, len(e))
+	for _, err := range e {
+		msg = append(msg, err.Error())
+	}
+	return fmt.Sprintf("%d errors occurred: %s", len(e), strings.Join(msg, "; "))
+}
@@ -0,0 +1,151 @@
+package alt
+
+import (
+	"reflect"
+)
+
+// ExplodeStrategy 列表展开的方式
+type ExplodeStrategy int
+
+const (
+	// ExplodeCartesian 同级的列表之间做笛卡尔积(默认)
+	ExplodeCartesian ExplodeStrategy = iota
+	// ExplodeZip 同级的列表按下标合并, 第 i 行由每个列表的第 i 个元素组成, 长度不足的列表使用 nil 填充, 即列表的字段值为 nil
+	ExplodeZip
+	// ExplodeFirst 只展开列表的第一个元素
+	ExplodeFirst
+	// ExplodeIndex 不展开为多行, 将每个元素输出为以下标命名的字段, 例如 tags.0, tags.1
+	ExplodeIndex
+)
+
+type explodeRule struct {
+	spec     pathSpec
+	strategy ExplodeStrategy
+}
+
+// SetExplode 设置列表的展开方式, 不指定 paths 时设置全局的展开方式
+// paths 为字段路径(格式见 pathSpec), 例如 SetExplode(ExplodeZip, "timestamps", "values")
+func SetExplode(strategy ExplodeStrategy, paths ...string) OptionFunc {
+	return func(c *dataEtl) {
+		if len(paths) == 0 {
+			c.explode = strategy
+			return
+		}
+		for _, spec := range parsePathSpecs(paths) {
+			c.explodeRules = append(c.explodeRules, explodeRule{spec: spec, strategy: strategy})
+		}
+	}
+}
+
+// explodeStrategy 返回路径对应的展开方式, 后设置的规则优先
+func (c *dataEtl) explodeStrategy(path []string) ExplodeStrategy {
+	for i := len(c.explodeRules) - 1; i >= 0; i-- {
+		if c.explodeRules[i].spec.match(path) {
+			return c.explodeRules[i].strategy
+		}
+	}
+	return c.explode
+}
+
+// arrayLen 返回列表需要展开的元素个数
+func (c *dataEtl) arrayLen(s reflect.Value) int {
+	if c.maxArrayLen > 0 && s.Len() > c.maxArrayLen {
+		return c.maxArrayLen
+	}
+	return s.Len()
+}
+
+// zippable 判断字段是否为需要按下标合并展开的列表
+// 超过深度, 需要序列化为 JSON 或者超过长度限制的列表仍然交给 parseSlice 处理
+func (c *dataEtl) zippable(f field, depth int) bool {
+	v := indirect(f.value)
+	if v == nil {
+		return false
+	}
+	if k := reflect.TypeOf(v).Kind(); k != reflect.Slice && k != reflect.Array {
+		return false
+	}
+	if c.explodeStrategy(f.path) != ExplodeZip || c.recordPath != nil {
+		return false
+	}
+	if c.maxDepth != Infinity && depth+1 > c.maxDepth {
+		return false
+	}
+	if _, ok := c.state.jsonPaths[f.prefix]; ok {
+		return false
+	}
+	if _, ok := c.fieldJSON(f); ok {
+		return false
+	}
+	if _, ok := c.leaf(f.value); ok {
+		return false
+	}
+	n := reflect.ValueOf(v).Len()
+	return n > 0 && (c.maxArrayLen <= 0 || n <= c.maxArrayLen || c.maxArrayLenPolicy == LimitTruncate)
+}
+
+// zipFields 将同级需要按下标合并的列表合并为一个字段
+func (c *dataEtl) zipFields(fields []field, depth int) []field {
+	var zip, rest []field
+	for _, f := range fields {
+		if c.zippable(f, depth) {
+			f.value = indirect(f.value)
+			zip = append(zip, f)
+		} else {
+			rest = append(rest, f)
+		}
+	}
+	if len(zip) < 2 {
+		return fields
+	}
+	return append(rest, field{zip: zip})
+}
+
+// zipElements 返回合并列表中第 i 个元素组成的字段
+func (c *dataEtl) zipElements(zip []field, i int) []field {
+	var elements = make([]field, 0, len(zip))
+	for _, f := range zip {
+		s := reflect.ValueOf(f.value)
+		if i >= c.arrayLen(s) {
+			// 长度不足时使用 nil 填充
+			elements = append(elements, field{key: f.key, prefix: f.prefix, path: appendPath(f.path, listElem), pad: true})
+			continue
+		}
+		elements = append(elements, field{key: f.key, prefix: f.prefix, path: appendPath(f.path, listElem), value: s.Index(i).Interface()})
+	}
+	return elements
+}
+
+// zipLen 返回合并列表中最长的长度
+func (c *dataEtl) zipLen(zip []field) (n int) {
+	for _, f := range zip {
+		if l := c.arrayLen(reflect.ValueOf(f.value)); l > n {
+			n = l
+		}
+	}
+	return n
+}
+
+// parseZip 按下标展开合并的列表
+func (c *dataEtl) parseZip(
+	zip []field,
+	currentMap map[string]interface{},
+	depth int,
+	emit emitFunc,
+) error {
+	for i, n := 0, c.zipLen(zip); i < n; i++ {
+		if err := c.parseFields(c.zipElements(zip, i), currentMap, depth+1, emit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countZip 计算合并列表展开的行数
+func (c *dataEtl) countZip(zip []field, depth int, arrays map[string]int) uint64 {
+	var rows uint64
+	for i, n := 0, c.zipLen(zip); i < n; i++ {
+		rows = addRows(rows, c.countFields(c.zipElements(zip, i), depth+1, arrays))
+	}
+	return rows
+}
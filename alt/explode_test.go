@@ -0,0 +1,71 @@
+package alt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_dataEtl_explode(t *testing.T) {
+	var data = map[string]interface{}{
+		"name":       "cpu",
+		"timestamps": []int{1, 2, 3},
+		"values":     []float64{0.5, 0.6},
+		"tags":       []string{"a", "b"},
+	}
+	tests := []struct {
+		name       string
+		opt        []OptionFunc
+		wantResult matrixKvPairs
+	}{
+		{
+			name: "zip_path",
+			opt:  []OptionFunc{SetExplode(ExplodeZip, "timestamps", "values")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "name", Value: "cpu"}, {Key: "tags", Value: "a"}, {Key: "timestamps", Value: 1}, {Key: "values", Value: 0.5}},
+				[]pair{{Key: "name", Value: "cpu"}, {Key: "tags", Value: "a"}, {Key: "timestamps", Value: 2}, {Key: "values", Value: 0.6}},
+				[]pair{{Key: "name", Value: "cpu"}, {Key: "tags", Value: "a"}, {Key: "timestamps", Value: 3}, {Key: "values", Value: nil}},
+				[]pair{{Key: "name", Value: "cpu"}, {Key: "tags", Value: "b"}, {Key: "timestamps", Value: 1}, {Key: "values", Value: 0.5}},
+				[]pair{{Key: "name", Value: "cpu"}, {Key: "tags", Value: "b"}, {Key: "timestamps", Value: 2}, {Key: "values", Value: 0.6}},
+				[]pair{{Key: "name", Value: "cpu"}, {Key: "tags", Value: "b"}, {Key: "timestamps", Value: 3}, {Key: "values", Value: nil}},
+			},
+		},
+		{
+			name: "zip_global_first_path",
+			opt:  []OptionFunc{SetExplode(ExplodeZip), SetExplode(ExplodeFirst, "tags")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "name", Value: "cpu"}, {Key: "tags", Value: "a"}, {Key: "timestamps", Value: 1}, {Key: "values", Value: 0.5}},
+				[]pair{{Key: "name", Value: "cpu"}, {Key: "tags", Value: "a"}, {Key: "timestamps", Value: 2}, {Key: "values", Value: 0.6}},
+				[]pair{{Key: "name", Value: "cpu"}, {Key: "tags", Value: "a"}, {Key: "timestamps", Value: 3}, {Key: "values", Value: nil}},
+			},
+		},
+		{
+			name: "first",
+			opt:  []OptionFunc{SetExplode(ExplodeFirst)},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "name", Value: "cpu"}, {Key: "tags", Value: "a"}, {Key: "timestamps", Value: 1}, {Key: "values", Value: 0.5}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkParse(t, newTestParser(tt.opt...), data, tt.wantResult)
+		})
+	}
+}
+
+func Test_dataEtl_explode_zipShort(t *testing.T) {
+	parser := newTestParser(SetExplode(ExplodeZip))
+	gotResult, err := parser.ParseE(map[string]interface{}{"timestamps": []int{1, 2, 3}, "values": []float64{0.5, 0.6}})
+	if err != nil {
+		t.Fatalf("ParseE() error = %v", err)
+	}
+	// 长度不足的列表使用 nil 填充
+	want := []map[string]interface{}{
+		{"timestamps": 1, "values": 0.5},
+		{"timestamps": 2, "values": 0.6},
+		{"timestamps": 3, "values": nil},
+	}
+	if !reflect.DeepEqual(gotResult, want) {
+		t.Errorf("ParseE() = %v, want %v", gotResult, want)
+	}
+}
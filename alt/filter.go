@@ -0,0 +1,59 @@
+package alt
+
+import (
+	"reflect"
+)
+
+// SetIgnorePaths 按字段路径(格式见 pathSpec)忽略字段, 与 SetIgnore 不同, 支持通配符
+// 例如 SetIgnorePaths("data.*.secret", "**._meta")
+func SetIgnorePaths(patterns ...string) OptionFunc {
+	return func(c *dataEtl) {
+		c.ignorePaths = append(c.ignorePaths, parsePathSpecs(patterns)...)
+	}
+}
+
+// SetInclude 只保留匹配的字段及其下的所有字段, 其他字段都会被忽略, 支持通配符
+// 例如 SetInclude("name", "data.*.id")
+func SetInclude(patterns ...string) OptionFunc {
+	return func(c *dataEtl) {
+		c.includePaths = append(c.includePaths, parsePathSpecs(patterns)...)
+	}
+}
+
+// ignored 判断字段是否被忽略, SetIgnore 按扁平化之后的字段名匹配, SetIgnorePaths 按字段路径匹配
+func (c *dataEtl) ignored(prefix string, path []string) bool {
+	if _, find := c.ignore[prefix]; find {
+		return true
+	}
+	for _, spec := range c.ignorePaths {
+		if spec.match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// included 判断字段是否在 SetInclude 的范围内
+// 字段或者它的父字段匹配时保留, 复杂类型的字段之下可能有匹配的字段时继续展开
+func (c *dataEtl) included(path []string, value interface{}) bool {
+	if len(c.includePaths) == 0 {
+		return true
+	}
+	var route bool
+	for _, spec := range c.includePaths {
+		if spec.covers(path) {
+			return true
+		}
+		route = route || spec.hasPrefix(path)
+	}
+	if _, ok := c.leaf(value); ok {
+		return false
+	}
+	value = indirect(value)
+	return route && value != nil && !c.primitive(reflect.TypeOf(value).Kind())
+}
+
+// skipField 判断对象的字段是否需要跳过
+func (c *dataEtl) skipField(prefix string, path []string, value interface{}) bool {
+	return c.ignored(prefix, path) || !c.included(path, value)
+}
@@ -0,0 +1,115 @@
+package alt
+
+import (
+	"testing"
+)
+
+func Test_dataEtl_filter(t *testing.T) {
+	type secret struct {
+		Id     int
+		Secret string
+	}
+	var data = map[string]interface{}{
+		"name":  "cpu",
+		"_meta": map[string]interface{}{"v": 1},
+		"data": map[string]interface{}{
+			"a": map[string]interface{}{"id": 1, "secret": "x", "_meta": "m"},
+			"b": secret{Id: 2, Secret: "y"},
+		},
+		"tags": []interface{}{"a", "b"},
+	}
+	tests := []struct {
+		name       string
+		opt        []OptionFunc
+		wantResult matrixKvPairs
+	}{
+		{
+			name: "ignore_paths",
+			opt:  []OptionFunc{SetIgnorePaths("data.*.secret", "data.*.Secret", "**._meta", "tags"), SetSeparator(StrSeparator("_"))},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "data_a_id", Value: 1}, {Key: "data_b_Id", Value: 2}, {Key: "name", Value: "cpu"}},
+			},
+		},
+		{
+			name: "include",
+			opt:  []OptionFunc{SetInclude("name", "data.*.id", "data.b.Id", "_meta")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "_meta.v", Value: 1}, {Key: "data.a.id", Value: 1}, {Key: "data.b.Id", Value: 2}, {Key: "name", Value: "cpu"}},
+			},
+		},
+		{
+			name: "include_ignore",
+			opt:  []OptionFunc{SetInclude("data", "tags"), SetIgnorePaths("**._meta", "data.a.secret", "data.b.Secret")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "data.a.id", Value: 1}, {Key: "data.b.Id", Value: 2}, {Key: "tags", Value: "a"}},
+				[]pair{{Key: "data.a.id", Value: 1}, {Key: "data.b.Id", Value: 2}, {Key: "tags", Value: "b"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkParse(t, newTestParser(tt.opt...), data, tt.wantResult)
+		})
+	}
+}
+
+func Test_dataEtl_filter_list(t *testing.T) {
+	// 列表元素的路径与列表相同, 同时 * 可以匹配列表元素的位置
+	var data = map[string]interface{}{
+		"data": []interface{}{
+			map[string]interface{}{"id": 1, "secret": "x"},
+			map[string]interface{}{"id": 2, "secret": "y"},
+		},
+	}
+	tests := []struct {
+		name       string
+		opt        []OptionFunc
+		wantResult matrixKvPairs
+	}{
+		{
+			name: "ignore_list_element",
+			opt:  []OptionFunc{SetIgnorePaths("data.secret")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "data.id", Value: 1}},
+				[]pair{{Key: "data.id", Value: 2}},
+			},
+		},
+		{
+			name: "wildcard_list_element",
+			opt:  []OptionFunc{SetIgnorePaths("data.*.secret")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "data.id", Value: 1}},
+				[]pair{{Key: "data.id", Value: 2}},
+			},
+		},
+		{
+			name: "double_wildcard_list_element",
+			opt:  []OptionFunc{SetIgnorePaths("**.secret")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "data.id", Value: 1}},
+				[]pair{{Key: "data.id", Value: 2}},
+			},
+		},
+		{
+			name: "include_wildcard_list_element",
+			opt:  []OptionFunc{SetInclude("data.*.id")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "data.id", Value: 1}},
+				[]pair{{Key: "data.id", Value: 2}},
+			},
+		},
+		{
+			name: "include_list_element",
+			opt:  []OptionFunc{SetInclude("data.id")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "data.id", Value: 1}},
+				[]pair{{Key: "data.id", Value: 2}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkParse(t, newTestParser(tt.opt...), data, tt.wantResult)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package alt
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NonASCIIPolicy 字段名中非 ASCII 字符的处理方式
+type NonASCIIPolicy int
+
+const (
+	// NonASCIIKeep 保留非 ASCII 字符(默认)
+	NonASCIIKeep NonASCIIPolicy = iota
+	// NonASCIITransliterate 将带音调的拉丁字母转写为 ASCII 字母, 例如 é -> e, 无法转写的字符(例如中文)按 NonASCIIHash 处理
+	NonASCIITransliterate
+	// NonASCIIHash 将连续的非 ASCII 字符替换为 x 加上 8 位十六进制的哈希值, 例如 用户名 -> x367cc513
+	NonASCIIHash
+)
+
+// KeyNormalizer 将扁平化之后的字段名规范化为 SQL 中可以使用的标识符
+// 依次处理非 ASCII 字符, snake_case 或者小写, 长度限制, 保留字只在生成 SQL 时通过 QuoteIdent 添加引号
+type KeyNormalizer struct {
+	// SnakeCase 将 camelCase 和 PascalCase 转为小写的 snake_case, 字母, 数字和 _ 之外的字符(包括 Separator)都替换为 _
+	// 以数字开头时添加 _ 前缀, 建议与 StrSeparator("_") 一起使用
+	SnakeCase bool
+	// Lower 将字段名转为小写
+	Lower bool
+	// NonASCII 非 ASCII 字符的处理方式
+	NonASCII NonASCIIPolicy
+	// MaxLen 字段名的最大长度(字节), 超过时截断并添加 _ 加上 8 位十六进制的哈希值, 保证截断之后的字段名不同, <= 0 时不限制
+	// 最小为 MinKeyLen, 更小的值按 MinKeyLen 处理
+	MaxLen int
+	// Quote QuoteIdent 为保留字添加的引号, 例如 MySQL, ClickHouse 使用 `, 为空时不处理保留字
+	// 引号不会写入输出的字段名, 否则会出现在 CSV 的表头以及 Schema 中
+	Quote string
+	// ReservedWords 保留字, 不区分大小写, 为 nil 时使用 DefaultReservedWords
+	ReservedWords map[string]struct{}
+}
+
+// MinKeyLen KeyNormalizer.MaxLen 的最小值, 截断时除了 _ 和 8 位哈希值之外至少保留 1 个字节
+const MinKeyLen = 10
+
+// DefaultReservedWords ClickHouse, Doris, MySQL 中常见的保留字
+var DefaultReservedWords = reservedWords(
+	"add", "all", "alter", "and", "as", "asc", "between", "by", "case", "create", "cross", "database",
+	"default", "delete", "desc", "distinct", "drop", "else", "end", "exists", "false", "from", "full",
+	"group", "having", "in", "index", "inner", "insert", "interval", "into", "is", "join", "key", "left",
+	"like", "limit", "not", "null", "on", "or", "order", "outer", "partition", "primary", "right", "select",
+	"set", "table", "then", "to", "true", "union", "update", "using", "values", "when", "where", "with",
+)
+
+func reservedWords(words ...string) map[string]struct{} {
+	var m = make(map[string]struct{}, len(words))
+	for _, w := range words {
+		m[w] = struct{}{}
+	}
+	return m
+}
+
+// SetKeyNormalizer 使用 KeyNormalizer 规范化输出的字段名, 在重命名之后处理
+// 规范化之后相同的字段名按照 CollisionStrategy 处理
+func SetKeyNormalizer(n KeyNormalizer) OptionFunc {
+	return func(c *dataEtl) {
+		c.normalizer = &n
+	}
+}
+
+// normalizeKey 返回规范化之后的字段名, 同一次解析中缓存规范化的结果
+func (c *dataEtl) normalizeKey(key string) string {
+	if c.normalizer == nil {
+		return key
+	}
+	if s, ok := c.state.names[key]; ok {
+		return s
+	}
+	s := c.normalizer.Normalize(key)
+	c.state.names[key] = s
+	return s
+}
+
+// Normalize 返回规范化之后的字段名, 不会为保留字添加引号
+func (n KeyNormalizer) Normalize(key string) string {
+	s := key
+	switch n.NonASCII {
+	case NonASCIITransliterate:
+		s = transliterate(s)
+	case NonASCIIHash:
+		s = hashNonASCII(s)
+	}
+	if n.SnakeCase {
+		s = snakeCase(s)
+		if s == "" {
+			s = "_" + hashKey(key)
+		} else if s[0] >= '0' && s[0] <= '9' {
+			s = "_" + s
+		}
+	} else if n.Lower {
+		s = strings.ToLower(s)
+	}
+	if max := n.MaxLen; max > 0 {
+		if max < MinKeyLen {
+			max = MinKeyLen
+		}
+		if len(s) > max {
+			s = truncate(s, max-9) + "_" + hashKey(key)
+		}
+	}
+	return s
+}
+
+// QuoteIdent 生成 SQL 时为保留字添加引号, 例如 order -> `order`, 其他的字段名保持不变
+func (n KeyNormalizer) QuoteIdent(name string) string {
+	if n.Quote == "" {
+		return name
+	}
+	words := n.ReservedWords
+	if words == nil {
+		words = DefaultReservedWords
+	}
+	if _, ok := words[strings.ToLower(name)]; ok {
+		return n.Quote + name + n.Quote
+	}
+	return name
+}
+
+// hashKey 返回 8 位十六进制的 fnv 哈希值
+func hashKey(s string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// truncate 截断到不超过 n 个字节, 不会截断多字节字符
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	for n < len(s) && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// hashNonASCII 将连续的非 ASCII 字符替换为 x 加上哈希值
+func hashNonASCII(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] < utf8.RuneSelf {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(s) && s[j] >= utf8.RuneSelf {
+			j++
+		}
+		b.WriteString("x" + hashKey(s[i:j]))
+		i = j
+	}
+	return b.String()
+}
+
+var latinLetters = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i",
+	'ł': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o",
+	'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ť': "t", 'ţ': "t",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'þ': "th", 'ð': "d",
+}
+
+// transliterate 将带音调的拉丁字母转写为 ASCII 字母, 无法转写的字符使用哈希值替换
+func transliterate(s string) string {
+	var (
+		b    strings.Builder
+		rest strings.Builder
+	)
+	flush := func() {
+		if rest.Len() > 0 {
+			b.WriteString("x" + hashKey(rest.String()))
+			rest.Reset()
+		}
+	}
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			flush()
+			b.WriteRune(r)
+			continue
+		}
+		lower := unicode.ToLower(r)
+		if t, ok := latinLetters[lower]; ok {
+			flush()
+			if lower != r {
+				t = strings.ToUpper(t[:1]) + t[1:]
+			}
+			b.WriteString(t)
+			continue
+		}
+		rest.WriteRune(r)
+	}
+	flush()
+	return b.String()
+}
+
+// snakeCase 转为小写的 snake_case, 例如 userName -> user_name, HTTPServer -> http_server, Home-Address -> home_address
+func snakeCase(s string) string {
+	var (
+		b     strings.Builder
+		runes = []rune(s)
+	)
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			// 小写字母或数字之后的大写字母, 以及连续大写字母中最后一个大写字母之前需要分隔
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	// 合并连续的 _ 并去掉首尾的 _, 保留原字段名开头的 _, 例如 _id
+	out := strings.Join(strings.FieldsFunc(b.String(), func(r rune) bool { return r == '_' }), "_")
+	if strings.HasPrefix(s, "_") {
+		return "_" + out
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package alt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeyNormalizer_Normalize(t *testing.T) {
+	tests := []struct {
+		name string
+		n    KeyNormalizer
+		key  string
+		want string
+	}{
+		{name: "snake_camel", n: KeyNormalizer{SnakeCase: true}, key: "userName", want: "user_name"},
+		{name: "snake_acronym", n: KeyNormalizer{SnakeCase: true}, key: "HTTPServer_ID", want: "http_server_id"},
+		{name: "snake_space", n: KeyNormalizer{SnakeCase: true}, key: " Home-Address . Zip Code ", want: "home_address_zip_code"},
+		{name: "snake_underscore", n: KeyNormalizer{SnakeCase: true}, key: "_id", want: "_id"},
+		{name: "snake_digit", n: KeyNormalizer{SnakeCase: true}, key: "2fa", want: "_2fa"},
+		{name: "snake_empty", n: KeyNormalizer{SnakeCase: true}, key: "--", want: "_" + hashKey("--")},
+		{name: "lower", n: KeyNormalizer{Lower: true}, key: "Data.UserName", want: "data.username"},
+		{name: "keep", n: KeyNormalizer{SnakeCase: true}, key: "用户 名", want: "用户_名"},
+		{name: "hash", n: KeyNormalizer{SnakeCase: true, NonASCII: NonASCIIHash}, key: "用户名.age", want: "x" + hashKey("用户名") + "_age"},
+		{name: "transliterate", n: KeyNormalizer{SnakeCase: true, NonASCII: NonASCIITransliterate}, key: "Café Größe 名", want: "cafe_grosse_x" + hashKey("名")},
+		{name: "max_len", n: KeyNormalizer{MaxLen: 16}, key: "abcdefghijklmnopqrstuvwxyz", want: "abcdefg_" + hashKey("abcdefghijklmnopqrstuvwxyz")},
+		{name: "max_len_rune", n: KeyNormalizer{MaxLen: 12}, key: "a用户名用户名", want: "a_" + hashKey("a用户名用户名")},
+		{name: "max_len_min", n: KeyNormalizer{MaxLen: 5}, key: "abcdefghijk", want: "a_" + hashKey("abcdefghijk")},
+		{name: "max_len_min_short", n: KeyNormalizer{MaxLen: 1}, key: "abcdefghij", want: "abcdefghij"},
+		{name: "reserved", n: KeyNormalizer{SnakeCase: true, Quote: "`"}, key: "Order", want: "order"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.n.Normalize(tt.key); got != tt.want {
+				t.Errorf("Normalize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyNormalizer_QuoteIdent(t *testing.T) {
+	tests := []struct {
+		name string
+		n    KeyNormalizer
+		key  string
+		want string
+	}{
+		{name: "quote", n: KeyNormalizer{Quote: "`"}, key: "order", want: "`order`"},
+		{name: "quote_custom", n: KeyNormalizer{Quote: `"`, ReservedWords: reservedWords("user")}, key: "USER", want: `"USER"`},
+		{name: "not_reserved", n: KeyNormalizer{Quote: "`"}, key: "orders", want: "orders"},
+		{name: "no_quote", n: KeyNormalizer{}, key: "order", want: "order"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.n.QuoteIdent(tt.key); got != tt.want {
+				t.Errorf("QuoteIdent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_dataEtl_normalizeKey(t *testing.T) {
+	parser := newTestParser(
+		SetSeparator(StrSeparator("_")),
+		SetKeyNormalizer(KeyNormalizer{SnakeCase: true, Quote: "`"}),
+		SetCollision(CollisionSuffix),
+	)
+	gotResult, err := parser.ParseE(map[string]interface{}{
+		"userInfo": map[string]interface{}{"First Name": "小明", "order": 1},
+		"Group":    "a",
+	})
+	if err != nil {
+		t.Fatalf("ParseE() error = %v", err)
+	}
+	want := matrixKvPairs{
+		[]pair{{Key: "group", Value: "a"}, {Key: "user_info_first_name", Value: "小明"}, {Key: "user_info_order", Value: 1}},
+	}
+	if !reflect.DeepEqual(covertHelper(gotResult), want) {
+		t.Errorf("ParseE() = %v, want %v", covertHelper(gotResult), want)
+	}
+}
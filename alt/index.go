@@ -0,0 +1,80 @@
+package alt
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// IndexRestKey ExplodeIndex 方式超过最大下标的元素序列化为 JSON 之后使用的字段名, 例如 tags.rest
+const IndexRestKey = "rest"
+
+// SetMaxIndex 设置 ExplodeIndex 方式最多输出的下标, n < 0 时不限制
+// 超过 n 的元素根据 policy 丢弃, 返回错误, 或者序列化为 JSON 字符串保存到 IndexRestKey 对应的字段
+func SetMaxIndex(n int, policy LimitPolicy) OptionFunc {
+	return func(c *dataEtl) {
+		if n < 0 {
+			c.maxIndexLen = 0
+		} else {
+			c.maxIndexLen = n + 1
+		}
+		c.maxIndexPolicy = policy
+	}
+}
+
+// indexFields 将列表的每个元素作为一个字段, 下标通过 Separator 追加到字段名
+// rest 为超过最大下标, 需要序列化为 JSON 的元素
+func (c *dataEtl) indexFields(data interface{}, prefix string, path []string) (fields []field, rest []interface{}) {
+	s := reflect.ValueOf(data)
+	n := s.Len()
+	if c.maxIndexLen > 0 && n > c.maxIndexLen {
+		if c.maxIndexPolicy == LimitJSON {
+			for i := c.maxIndexLen; i < n; i++ {
+				rest = append(rest, s.Index(i).Interface())
+			}
+		}
+		n = c.maxIndexLen
+	}
+	for i := 0; i < n; i++ {
+		fields = append(fields, field{
+			key:    i,
+			prefix: c.separator.AppendToPrefix(prefix, i),
+			path:   appendPath(path, i),
+			value:  s.Index(i).Interface(),
+		})
+	}
+	return fields, rest
+}
+
+// parseIndex 将列表展开为以下标命名的字段, 不会产生新的行
+func (c *dataEtl) parseIndex(
+	data interface{},
+	prefix string,
+	path []string,
+	currentMap map[string]interface{},
+	depth int,
+	emit emitFunc,
+) error {
+	s := reflect.ValueOf(data)
+	if c.maxIndexLen > 0 && s.Len() > c.maxIndexLen {
+		c.logger.Warn(fmt.Sprintf("max index: %s has %d elements, more than max index %d", prefix, s.Len(), c.maxIndexLen-1))
+		if c.maxIndexPolicy == LimitError {
+			return &ParseError{Path: prefix, Kind: s.Kind(), Depth: depth, Err: ErrMaxIndex}
+		}
+	}
+	fields, rest := c.indexFields(data, prefix, path)
+	tmp := currentMap
+	if rest != nil {
+		v, err := c.marshalValue(rest, prefix, depth, JSONString)
+		if err != nil {
+			return err
+		}
+		if v != nil {
+			tmp = cpm(currentMap)
+			err = c.put(tmp, c.separator.AppendToPrefix(prefix, IndexRestKey), appendPath(path, IndexRestKey), v, depth)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return c.parseObject(fields, tmp, depth, emit)
+}
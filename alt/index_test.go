@@ -0,0 +1,94 @@
+package alt
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_dataEtl_parseIndex(t *testing.T) {
+	var data = map[string]interface{}{
+		"name": "cpu",
+		"tags": []interface{}{"a", "b", "c"},
+		"data": []interface{}{
+			map[string]interface{}{"id": 1, "values": []int{1, 2}},
+		},
+	}
+	tests := []struct {
+		name       string
+		opt        []OptionFunc
+		wantResult matrixKvPairs
+		wantErr    error
+	}{
+		{
+			name: "index_path",
+			opt:  []OptionFunc{SetExplode(ExplodeIndex, "tags")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "data.id", Value: 1}, {Key: "data.values", Value: 1}, {Key: "name", Value: "cpu"}, {Key: "tags.0", Value: "a"}, {Key: "tags.1", Value: "b"}, {Key: "tags.2", Value: "c"}},
+				[]pair{{Key: "data.id", Value: 1}, {Key: "data.values", Value: 2}, {Key: "name", Value: "cpu"}, {Key: "tags.0", Value: "a"}, {Key: "tags.1", Value: "b"}, {Key: "tags.2", Value: "c"}},
+			},
+		},
+		{
+			name: "index_global",
+			opt:  []OptionFunc{SetExplode(ExplodeIndex), SetSeparator(StrSeparator("_"))},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "data_0_id", Value: 1}, {Key: "data_0_values_0", Value: 1}, {Key: "data_0_values_1", Value: 2}, {Key: "name", Value: "cpu"}, {Key: "tags_0", Value: "a"}, {Key: "tags_1", Value: "b"}, {Key: "tags_2", Value: "c"}},
+			},
+		},
+		{
+			name: "max_index_truncate",
+			opt:  []OptionFunc{SetExplode(ExplodeIndex), SetMaxIndex(0, LimitTruncate)},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "data.0.id", Value: 1}, {Key: "data.0.values.0", Value: 1}, {Key: "name", Value: "cpu"}, {Key: "tags.0", Value: "a"}},
+			},
+		},
+		{
+			name: "max_index_json",
+			opt:  []OptionFunc{SetExplode(ExplodeIndex, "tags"), SetMaxIndex(1, LimitJSON), SetExplode(ExplodeFirst, "data.values")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "data.id", Value: 1}, {Key: "data.values", Value: 1}, {Key: "name", Value: "cpu"}, {Key: "tags.0", Value: "a"}, {Key: "tags.1", Value: "b"}, {Key: "tags.rest", Value: `["c"]`}},
+			},
+		},
+		{
+			name:    "max_index_error",
+			opt:     []OptionFunc{SetExplode(ExplodeIndex, "tags"), SetMaxIndex(1, LimitError)},
+			wantErr: ErrMaxIndex,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := newTestParser(tt.opt...)
+			gotResult, err := parser.ParseE(data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(covertHelper(gotResult), tt.wantResult) {
+				t.Errorf("ParseE() = %v, want %v", covertHelper(gotResult), tt.wantResult)
+			}
+			if err != nil {
+				return
+			}
+			if got := parser.(*dataEtl).fork().countDocument(data, nil); got != uint64(len(tt.wantResult)) {
+				t.Errorf("countDocument() = %v, want %v", got, len(tt.wantResult))
+			}
+		})
+	}
+}
+
+func Test_dataEtl_ParseTables_index(t *testing.T) {
+	var data = map[string]interface{}{"tags": []string{"a", "b"}}
+	gotTables, err := NewRelationalParser(
+		SetLogger(NewStdLogger(LevelError, os.Stdout)),
+		SetExplode(ExplodeIndex, "tags"),
+	).ParseTables(data)
+	if err != nil {
+		t.Fatalf("ParseTables() error = %v", err)
+	}
+	want := map[string][]map[string]interface{}{
+		"root": {{"_id": int64(1), "tags.0": "a", "tags.1": "b"}},
+	}
+	if !reflect.DeepEqual(gotTables, want) {
+		t.Errorf("ParseTables() = %v, want %v", gotTables, want)
+	}
+}
@@ -0,0 +1,97 @@
+package alt
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// JSONFormat 序列化为 JSON 之后输出的类型
+type JSONFormat int
+
+const (
+	// JSONString 输出 JSON 字符串
+	JSONString JSONFormat = iota
+	// JSONRaw 输出 json.RawMessage
+	JSONRaw
+)
+
+type jsonRule struct {
+	spec   pathSpec
+	format JSONFormat
+}
+
+// SetKeepJSON 不再展开 paths 对应的字段, 将整个字段序列化为 JSON 保存到扁平化之后的字段
+// paths 为字段路径(格式见 pathSpec), 例如 SetKeepJSON(JSONString, "attributes")
+func SetKeepJSON(format JSONFormat, paths ...string) OptionFunc {
+	return func(c *dataEtl) {
+		for _, spec := range parsePathSpecs(paths) {
+			c.jsonRules = append(c.jsonRules, jsonRule{spec: spec, format: format})
+		}
+	}
+}
+
+// keepJSON 判断路径对应的字段是否需要保存为 JSON, 后设置的规则优先
+func (c *dataEtl) keepJSON(path []string) (format JSONFormat, ok bool) {
+	for i := len(c.jsonRules) - 1; i >= 0; i-- {
+		if c.jsonRules[i].spec.match(path) {
+			return c.jsonRules[i].format, true
+		}
+	}
+	return JSONString, false
+}
+
+// fieldJSON 判断对象的字段是否需要保存为 JSON, struct tag 的设置优先
+func (c *dataEtl) fieldJSON(f field) (format JSONFormat, ok bool) {
+	if f.json {
+		return f.format, true
+	}
+	return c.keepJSON(f.path)
+}
+
+// parseJSON 将字段序列化为 JSON, 作为一个字段输出
+func (c *dataEtl) parseJSON(
+	data interface{},
+	prefix string,
+	path []string,
+	currentMap map[string]interface{},
+	depth int,
+	format JSONFormat,
+	emit emitFunc,
+) error {
+	v, err := c.marshalValue(data, prefix, depth, format)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return emit(currentMap)
+	}
+	tmp := cpm(currentMap)
+	if err := c.put(tmp, prefix, path, v, depth); err != nil {
+		return err
+	}
+	return emit(tmp)
+}
+
+// marshalValue 按照 format 序列化字段, 序列化失败时根据 ErrorMode 处理, 返回 nil
+func (c *dataEtl) marshalValue(data interface{}, prefix string, depth int, format JSONFormat) (interface{}, error) {
+	v, err := marshal(data)
+	if err != nil {
+		return nil, c.report(&ParseError{Path: prefix, Kind: reflect.TypeOf(data).Kind(), Depth: depth, Err: err})
+	}
+	if format == JSONRaw {
+		return json.RawMessage(v), nil
+	}
+	return v, nil
+}
+
+// marshal 序列化为 JSON 字符串, 不转义 HTML 字符
+func marshal(data interface{}) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(data); err != nil {
+		return "", err
+	}
+	return string(bytes.TrimRight(buf.Bytes(), "\n")), nil
+}
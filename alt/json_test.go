@@ -0,0 +1,76 @@
+package alt
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_dataEtl_keepJSON(t *testing.T) {
+	var data = map[string]interface{}{
+		"name":       "cpu",
+		"attributes": map[string]interface{}{"host": "a<b", "ports": []int{80, 443}},
+		"tags":       []interface{}{"a", "b"},
+		"data":       []interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"id": 2}},
+	}
+	tests := []struct {
+		name       string
+		opt        []OptionFunc
+		wantResult matrixKvPairs
+	}{
+		{
+			name: "object_string",
+			opt:  []OptionFunc{SetKeepJSON(JSONString, "attributes")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "attributes", Value: `{"host":"a<b","ports":[80,443]}`}, {Key: "data.id", Value: 1}, {Key: "name", Value: "cpu"}, {Key: "tags", Value: "a"}},
+				[]pair{{Key: "attributes", Value: `{"host":"a<b","ports":[80,443]}`}, {Key: "data.id", Value: 1}, {Key: "name", Value: "cpu"}, {Key: "tags", Value: "b"}},
+				[]pair{{Key: "attributes", Value: `{"host":"a<b","ports":[80,443]}`}, {Key: "data.id", Value: 2}, {Key: "name", Value: "cpu"}, {Key: "tags", Value: "a"}},
+				[]pair{{Key: "attributes", Value: `{"host":"a<b","ports":[80,443]}`}, {Key: "data.id", Value: 2}, {Key: "name", Value: "cpu"}, {Key: "tags", Value: "b"}},
+			},
+		},
+		{
+			name: "array_raw",
+			opt:  []OptionFunc{SetKeepJSON(JSONRaw, "tags", "data", "attributes.ports"), SetSeparator(StrSeparator("_"))},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "attributes_host", Value: "a<b"}, {Key: "attributes_ports", Value: json.RawMessage(`[80,443]`)}, {Key: "data", Value: json.RawMessage(`[{"id":1},{"id":2}]`)}, {Key: "name", Value: "cpu"}, {Key: "tags", Value: json.RawMessage(`["a","b"]`)}},
+			},
+		},
+		{
+			name: "primitive",
+			opt:  []OptionFunc{SetKeepJSON(JSONString, "name", "tags", "data")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "attributes.host", Value: "a<b"}, {Key: "attributes.ports", Value: 80}, {Key: "data", Value: `[{"id":1},{"id":2}]`}, {Key: "name", Value: `"cpu"`}, {Key: "tags", Value: `["a","b"]`}},
+				[]pair{{Key: "attributes.host", Value: "a<b"}, {Key: "attributes.ports", Value: 443}, {Key: "data", Value: `[{"id":1},{"id":2}]`}, {Key: "name", Value: `"cpu"`}, {Key: "tags", Value: `["a","b"]`}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkParse(t, newTestParser(tt.opt...), data, tt.wantResult)
+		})
+	}
+}
+
+func Test_dataEtl_ParseTables_keepJSON(t *testing.T) {
+	var data = map[string]interface{}{
+		"name": "cpu",
+		"tags": []interface{}{"a", "b"},
+		"data": []interface{}{map[string]interface{}{"id": 1, "attributes": map[string]interface{}{"k": "v"}}},
+	}
+	parser := NewRelationalParser(
+		SetLogger(NewStdLogger(LevelError, os.Stdout)),
+		SetKeepJSON(JSONString, "tags", "data.attributes"),
+	)
+	tables, err := parser.ParseTables(data)
+	if err != nil {
+		t.Fatalf("ParseTables() error = %v", err)
+	}
+	want := map[string][]map[string]interface{}{
+		RootTable: {{"_id": int64(1), "name": "cpu", "tags": `["a","b"]`}},
+		"data":    {{"_id": int64(2), "_parent_id": int64(1), "id": 1, "attributes": `{"k":"v"}`}},
+	}
+	if !reflect.DeepEqual(tables, want) {
+		t.Errorf("ParseTables() = %v, want %v", tables, want)
+	}
+}
@@ -0,0 +1,130 @@
+package alt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LineError JSON Lines 中某一行的错误, Line 从 1 开始, Offset 为该行第一个字节的偏移量
+type LineError struct {
+	Line   int
+	Offset int64
+	Err    error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d (offset %d): %v", e.Line, e.Offset, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// LineErrors 解析 JSON Lines 时收集到的所有行的错误
+type LineErrors []*LineError
+
+func (e LineErrors) Error() string {
+	msg := make([]string, 0, len(e))
+	for _, err := range e {
+		msg = append(msg, err.Error())
+	}
+	return fmt.Sprintf("%d lines failed: %s", len(e), strings.Join(msg, "; "))
+}
+
+// JSONLinesReader 逐行解码 JSON Lines(NDJSON), 数字解码为 json.Number, 空行会被跳过
+// 某一行格式错误时返回 *LineError, 可以继续读取之后的行
+type JSONLinesReader struct {
+	r      *bufio.Reader
+	line   int
+	offset int64
+	// 最近一次返回的文档所在的行和偏移量
+	docLine   int
+	docOffset int64
+}
+
+func NewJSONLinesReader(r io.Reader) *JSONLinesReader {
+	return &JSONLinesReader{r: bufio.NewReader(r)}
+}
+
+// Next 返回下一个文档, 没有更多的文档时返回 io.EOF
+func (r *JSONLinesReader) Next() (doc interface{}, err error) {
+	for {
+		b, err := r.r.ReadBytes('\n')
+		if len(b) == 0 && err != nil {
+			return nil, err
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		r.line++
+		line, offset := r.line, r.offset
+		r.offset += int64(len(b))
+		b = bytes.TrimSpace(b)
+		if len(b) == 0 {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			return nil, &LineError{Line: line, Offset: offset, Err: err}
+		}
+		if dec.More() {
+			return nil, &LineError{Line: line, Offset: offset, Err: fmt.Errorf("unexpected data after offset %d", dec.InputOffset())}
+		}
+		r.docLine, r.docOffset = line, offset
+		return doc, nil
+	}
+}
+
+// Position 返回最近一次 Next 返回的文档所在的行和偏移量
+func (r *JSONLinesReader) Position() (line int, offset int64) {
+	return r.docLine, r.docOffset
+}
+
+// ParseJSONLines 使用 parser 逐个解析 JSON Lines 中的文档, 每生成一行调用一次 fn
+// 格式错误或者解析失败的行不会中断解析, 结束之后以 LineErrors 返回
+// fn 返回 ErrStop 时提前结束, 返回其他错误时中断解析并返回该错误
+func ParseJSONLines(r io.Reader, parser Parser, fn func(row map[string]interface{}) error) error {
+	var (
+		jr   = NewJSONLinesReader(r)
+		errs LineErrors
+	)
+	for {
+		doc, err := jr.Next()
+		if err == io.EOF {
+			break
+		}
+		var lineErr *LineError
+		if errors.As(err, &lineErr) {
+			errs = append(errs, lineErr)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		var fnErr error
+		err = parser.ParseEach(doc, func(row map[string]interface{}) error {
+			fnErr = fn(row)
+			return fnErr
+		})
+		if errors.Is(fnErr, ErrStop) {
+			break
+		}
+		if fnErr != nil {
+			return fnErr
+		}
+		if err != nil {
+			line, offset := jr.Position()
+			errs = append(errs, &LineError{Line: line, Offset: offset, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
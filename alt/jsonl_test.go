@@ -0,0 +1,90 @@
+package alt
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const jsonLines = `{"name": "map", "data": [{"age": 18}, {"age": 17.5}]}
+
+{"name": "bad",
+{"name": "big", "id": 12345678901234567890}
+{"name": "two"} {"name": "objects"}
+[1, 2]
+`
+
+func TestJSONLinesReader(t *testing.T) {
+	r := NewJSONLinesReader(strings.NewReader(jsonLines))
+	var (
+		docs []interface{}
+		errs []*LineError
+	)
+	for {
+		doc, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		var lineErr *LineError
+		if errors.As(err, &lineErr) {
+			errs = append(errs, lineErr)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		docs = append(docs, doc)
+	}
+	wantDocs := []interface{}{
+		map[string]interface{}{"name": "map", "data": []interface{}{
+			map[string]interface{}{"age": json.Number("18")},
+			map[string]interface{}{"age": json.Number("17.5")},
+		}},
+		map[string]interface{}{"name": "big", "id": json.Number("12345678901234567890")},
+		[]interface{}{json.Number("1"), json.Number("2")},
+	}
+	if !reflect.DeepEqual(docs, wantDocs) {
+		t.Errorf("Next() = %v, want %v", docs, wantDocs)
+	}
+	if len(errs) != 2 || errs[0].Line != 3 || errs[0].Offset != 55 || errs[1].Line != 5 || errs[1].Offset != 115 {
+		t.Errorf("Next() errors = %v, want line 3 offset 55 and line 5 offset 115", errs)
+	}
+}
+
+func TestParseJSONLines(t *testing.T) {
+	parser := newTestParser(SetMaxRows(1, LimitError))
+	var rows []map[string]interface{}
+	err := ParseJSONLines(strings.NewReader(jsonLines), parser, func(row map[string]interface{}) error {
+		rows = append(rows, row)
+		return nil
+	})
+	want := []map[string]interface{}{{"name": "big", "id": json.Number("12345678901234567890")}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("ParseJSONLines() = %v, want %v", rows, want)
+	}
+	// 第 1 行超过最大行数, 第 3, 5 行格式错误, 第 6 行超过最大行数
+	errs, ok := err.(LineErrors)
+	if !ok || len(errs) != 4 {
+		t.Fatalf("ParseJSONLines() error = %v, want 4 line errors", err)
+	}
+	var lines []int
+	for _, e := range errs {
+		lines = append(lines, e.Line)
+	}
+	if !reflect.DeepEqual(lines, []int{1, 3, 5, 6}) || !errors.Is(errs[0], ErrMaxRows) {
+		t.Errorf("ParseJSONLines() error = %v", err)
+	}
+
+	// fn 返回 ErrStop 时提前结束
+	var n int
+	err = ParseJSONLines(strings.NewReader(jsonLines), newTestParser(), func(row map[string]interface{}) error {
+		n++
+		return ErrStop
+	})
+	if err != nil || n != 1 {
+		t.Errorf("ParseJSONLines() error = %v, rows = %d, want nil and 1", err, n)
+	}
+}
@@ -0,0 +1,146 @@
+package alt
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"time"
+)
+
+// LeafFunc 将叶子类型的值转换为输出的值, 返回 nil 时不输出该字段
+type LeafFunc func(v interface{}) interface{}
+
+// defaultLeaves 内置的叶子类型, 叶子类型的值不会继续展开, 作为一个字段输出
+var defaultLeaves = map[reflect.Type]LeafFunc{
+	reflect.TypeOf(time.Time{}):       LeafKeep,
+	reflect.TypeOf(time.Duration(0)):  LeafKeep,
+	reflect.TypeOf(json.Number("")):   LeafKeep,
+	reflect.TypeOf(json.RawMessage{}): LeafKeep,
+	reflect.TypeOf([]byte(nil)):       LeafBase64,
+	reflect.TypeOf((*big.Int)(nil)):   LeafString,
+	reflect.TypeOf((*big.Float)(nil)): leafBigFloat,
+	reflect.TypeOf(net.IP(nil)):       LeafString,
+	reflect.TypeOf([16]byte{}):        LeafUUID,
+}
+
+// SetLeaf 注册叶子类型及其输出方式, 类型由 sample 决定, 例如 SetLeaf(time.Time{}, LeafTimeFormat(time.RFC3339))
+// 可以覆盖内置的叶子类型, fn 为 nil 时取消注册, 该类型的值按照原来的方式展开
+func SetLeaf(sample interface{}, fn LeafFunc) OptionFunc {
+	return func(c *dataEtl) {
+		if c.leaves == nil {
+			c.leaves = make(map[reflect.Type]LeafFunc)
+		}
+		c.leaves[reflect.TypeOf(sample)] = fn
+	}
+}
+
+var (
+	bytesType         = reflect.TypeOf([]byte(nil))
+	uuidType          = reflect.TypeOf([16]byte{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// leafFunc 返回类型对应的 LeafFunc, SetLeaf 注册的优先
+// 没有注册的类型底层为 []byte 或者 [16]byte 时, 例如 type UUID [16]byte, 实现了 encoding.TextMarshaler 的输出 MarshalText 的结果,
+// 否则与底层类型的输出方式相同
+func (c *dataEtl) leafFunc(t reflect.Type) LeafFunc {
+	if fn, ok := c.leaves[t]; ok {
+		return fn
+	}
+	if fn, ok := defaultLeaves[t]; ok {
+		return fn
+	}
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array || t.Elem().Kind() != reflect.Uint8 {
+		return nil
+	}
+	switch {
+	case t.Implements(textMarshalerType):
+		return LeafText
+	case t.Kind() == reflect.Slice:
+		return c.leafFunc(bytesType)
+	case t.Len() == uuidType.Len():
+		return c.leafFunc(uuidType)
+	}
+	return nil
+}
+
+// leaf 判断值是否为叶子类型, 返回转换之后的值, 指针会被解引用之后再次判断
+func (c *dataEtl) leaf(data interface{}) (interface{}, bool) {
+	if data == nil {
+		return nil, false
+	}
+	for rv := reflect.ValueOf(data); ; rv = rv.Elem() {
+		// nil 指针作为 nil 处理
+		isPtr := rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface
+		if isPtr && rv.IsNil() {
+			return nil, false
+		}
+		if fn := c.leafFunc(rv.Type()); fn != nil {
+			return fn(rv.Interface()), true
+		}
+		if !isPtr {
+			return nil, false
+		}
+	}
+}
+
+// LeafKeep 原样输出
+func LeafKeep(v interface{}) interface{} {
+	return v
+}
+
+// LeafString 输出 String() 或者 fmt.Sprint 的结果
+func LeafString(v interface{}) interface{} {
+	if s, ok := v.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// LeafText 输出 encoding.TextMarshaler 的结果, 出错时不输出该字段
+func LeafText(v interface{}) interface{} {
+	b, err := v.(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return nil
+	}
+	return string(b)
+}
+
+// LeafBase64 []byte 输出为 base64 字符串, 与 encoding/json 一致
+func LeafBase64(v interface{}) interface{} {
+	return base64.StdEncoding.EncodeToString(reflect.ValueOf(v).Bytes())
+}
+
+// LeafHex []byte 输出为 16 进制字符串
+func LeafHex(v interface{}) interface{} {
+	return hex.EncodeToString(reflect.ValueOf(v).Bytes())
+}
+
+// LeafUUID [16]byte 输出为 UUID 格式的字符串, 例如 6ba7b810-9dad-11d1-80b4-00c04fd430c8
+func LeafUUID(v interface{}) interface{} {
+	var b [16]byte
+	reflect.Copy(reflect.ValueOf(b[:]), reflect.ValueOf(v))
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// LeafTimeFormat time.Time 按照 layout 输出为字符串
+func LeafTimeFormat(layout string) LeafFunc {
+	return func(v interface{}) interface{} {
+		return v.(time.Time).Format(layout)
+	}
+}
+
+// LeafUnixMilli time.Time 输出为毫秒时间戳
+func LeafUnixMilli(v interface{}) interface{} {
+	return v.(time.Time).UnixNano() / int64(time.Millisecond)
+}
+
+// leafBigFloat 输出能够精确表示 big.Float 的最短字符串
+func leafBigFloat(v interface{}) interface{} {
+	return v.(*big.Float).Text('g', -1)
+}
@@ -0,0 +1,110 @@
+package alt
+
+import (
+	"encoding/json"
+	"math/big"
+	"net"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// uuid 基于 [16]byte 定义的类型
+type uuid [16]byte
+
+// blob 基于 []byte 定义的类型
+type blob []byte
+
+// hash 实现了 encoding.TextMarshaler 的 []byte
+type hash []byte
+
+func (h hash) MarshalText() ([]byte, error) {
+	return []byte("sha:" + string(h)), nil
+}
+
+func Test_dataEtl_leaf(t *testing.T) {
+	type event struct {
+		Time     time.Time
+		Elapsed  time.Duration
+		Count    json.Number
+		Payload  []byte
+		Big      *big.Int
+		Ratio    *big.Float
+		Ip       net.IP
+		Id       [16]byte
+		Uuid     uuid
+		Blob     blob
+		Hash     hash
+		Raw      json.RawMessage
+		Nil      *big.Int
+		Previous *time.Time
+	}
+	var now = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	var ratio, _ = new(big.Float).SetPrec(200).SetString("1.00000000000000000001")
+	var bigInt, _ = new(big.Int).SetString("123456789012345678901234567890", 10)
+	var data = map[string]interface{}{
+		"event": event{
+			Time:     now,
+			Elapsed:  time.Second,
+			Count:    json.Number("12"),
+			Payload:  []byte("hello"),
+			Big:      bigInt,
+			Ratio:    ratio,
+			Ip:       net.IPv4(127, 0, 0, 1),
+			Id:       [16]byte{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8},
+			Uuid:     uuid{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8},
+			Blob:     blob("hello"),
+			Hash:     hash("abc"),
+			Raw:      json.RawMessage(`{"k":1}`),
+			Previous: &now,
+		},
+		"times": []time.Time{now, now.Add(time.Hour)},
+	}
+	tests := []struct {
+		name       string
+		opt        []OptionFunc
+		wantResult matrixKvPairs
+	}{
+		{
+			name: "default",
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "event.Big", Value: "123456789012345678901234567890"}, {Key: "event.Blob", Value: "aGVsbG8="}, {Key: "event.Count", Value: json.Number("12")}, {Key: "event.Elapsed", Value: time.Second}, {Key: "event.Hash", Value: "sha:abc"}, {Key: "event.Id", Value: "6ba7b810-9dad-11d1-80b4-00c04fd430c8"}, {Key: "event.Ip", Value: "127.0.0.1"}, {Key: "event.Payload", Value: "aGVsbG8="}, {Key: "event.Previous", Value: now}, {Key: "event.Ratio", Value: "1.00000000000000000001"}, {Key: "event.Raw", Value: json.RawMessage(`{"k":1}`)}, {Key: "event.Time", Value: now}, {Key: "event.Uuid", Value: "6ba7b810-9dad-11d1-80b4-00c04fd430c8"}, {Key: "times", Value: now}},
+				[]pair{{Key: "event.Big", Value: "123456789012345678901234567890"}, {Key: "event.Blob", Value: "aGVsbG8="}, {Key: "event.Count", Value: json.Number("12")}, {Key: "event.Elapsed", Value: time.Second}, {Key: "event.Hash", Value: "sha:abc"}, {Key: "event.Id", Value: "6ba7b810-9dad-11d1-80b4-00c04fd430c8"}, {Key: "event.Ip", Value: "127.0.0.1"}, {Key: "event.Payload", Value: "aGVsbG8="}, {Key: "event.Previous", Value: now}, {Key: "event.Ratio", Value: "1.00000000000000000001"}, {Key: "event.Raw", Value: json.RawMessage(`{"k":1}`)}, {Key: "event.Time", Value: now}, {Key: "event.Uuid", Value: "6ba7b810-9dad-11d1-80b4-00c04fd430c8"}, {Key: "times", Value: now.Add(time.Hour)}},
+			},
+		},
+		{
+			name: "custom",
+			opt: []OptionFunc{
+				SetLeaf(time.Time{}, LeafTimeFormat(time.RFC3339)),
+				SetLeaf([]byte(nil), LeafHex),
+				SetLeaf([16]byte{}, nil),
+				SetIgnorePaths("times", "event.Big", "event.Ratio", "event.Count", "event.Ip", "event.Hash", "event.Raw"),
+			},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "event.Blob", Value: "68656c6c6f"}, {Key: "event.Elapsed", Value: time.Second}, {Key: "event.Id", Value: uint8(0x6b)}, {Key: "event.Payload", Value: "68656c6c6f"}, {Key: "event.Previous", Value: "2021-01-02T03:04:05Z"}, {Key: "event.Time", Value: "2021-01-02T03:04:05Z"}, {Key: "event.Uuid", Value: uint8(0x6b)}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkParse(t, newTestParser(append([]OptionFunc{SetExplode(ExplodeFirst, "event.Id", "event.Uuid")}, tt.opt...)...), data, tt.wantResult)
+		})
+	}
+}
+
+func Test_dataEtl_ParseTables_leaf(t *testing.T) {
+	var now = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	parser := NewRelationalParser(SetLogger(NewStdLogger(LevelError, os.Stdout)))
+	tables, err := parser.ParseTables(map[string]interface{}{"time": now, "payloads": [][]byte{[]byte("a")}})
+	if err != nil {
+		t.Fatalf("ParseTables() error = %v", err)
+	}
+	want := map[string][]map[string]interface{}{
+		RootTable:  {{"_id": int64(1), "time": now}},
+		"payloads": {{"_id": int64(2), "_parent_id": int64(1), "_value": "YQ=="}},
+	}
+	if !reflect.DeepEqual(tables, want) {
+		t.Errorf("ParseTables() = %v, want %v", tables, want)
+	}
+}
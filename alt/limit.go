@@ -0,0 +1,205 @@
+package alt
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// LimitPolicy 超过 SetMaxRows, SetMaxArrayLen 限制时的处理方式
+type LimitPolicy int
+
+const (
+	// LimitTruncate 丢弃超出限制的行或列表元素
+	LimitTruncate LimitPolicy = iota
+	// LimitError 返回错误, 拒绝整个文档
+	LimitError
+	// LimitJSON 不再展开超出限制的列表, 将列表序列化为 JSON 字符串保存到列表对应的字段
+	// zip 和 index 方式展开的列表不会被序列化, 序列化其他列表之后仍然超过 SetMaxRows 时按 LimitError 处理
+	LimitJSON
+)
+
+// SetMaxRows 限制单个文档最多展开的行数, n <= 0 时不限制
+func SetMaxRows(n int, policy LimitPolicy) OptionFunc {
+	return func(c *dataEtl) {
+		c.maxRows = n
+		c.maxRowsPolicy = policy
+	}
+}
+
+// SetMaxArrayLen 限制列表最多展开的元素个数, n <= 0 时不限制
+func SetMaxArrayLen(n int, policy LimitPolicy) OptionFunc {
+	return func(c *dataEtl) {
+		c.maxArrayLen = n
+		c.maxArrayLenPolicy = policy
+	}
+}
+
+// checkRows 解析之前预估文档展开的行数, 超过 maxRows 时根据策略处理
+func (c *dataEtl) checkRows(data interface{}) error {
+	if c.maxRows <= 0 {
+		return nil
+	}
+	var arrays = make(map[string]int)
+	rows := c.countDocument(data, arrays)
+	if rows <= uint64(c.maxRows) {
+		return nil
+	}
+	path, found := c.explodePath(data, arrays)
+	switch c.maxRowsPolicy {
+	case LimitError:
+		msg := fmt.Sprintf("max rows: document expands to %d rows, more than %d, caused by %s", rows, c.maxRows, path)
+		c.logger.Warn(msg)
+		return &ParseError{Path: path, Kind: reflect.Slice, Depth: arrays[path], Err: ErrMaxRows}
+	case LimitJSON:
+		// 每次序列化一个对行数影响最大的列表, 直到行数满足限制
+		for ; rows > uint64(c.maxRows) && found; path, found = c.explodePath(data, arrays) {
+			c.logger.Warn(fmt.Sprintf("max rows: document expands to %d rows, more than %d, serialize %s as json", rows, c.maxRows, path))
+			c.state.jsonPaths[path] = struct{}{}
+			rows = c.countDocument(data, nil)
+		}
+		if rows > uint64(c.maxRows) {
+			c.logger.Warn(fmt.Sprintf("max rows: document expands to %d rows, more than %d, no more list can be serialized", rows, c.maxRows))
+			return &ParseError{Path: path, Kind: reflect.Slice, Depth: arrays[path], Err: ErrMaxRows}
+		}
+	default:
+		c.logger.Warn(fmt.Sprintf("max rows: document expands to %d rows, more than %d, caused by %s, truncate", rows, c.maxRows, path))
+	}
+	return nil
+}
+
+// explodePath 找出对行数影响最大的列表, 即序列化之后文档展开的行数最少的列表
+func (c *dataEtl) explodePath(data interface{}, arrays map[string]int) (path string, found bool) {
+	var paths = make([]string, 0, len(arrays))
+	for p := range arrays {
+		if _, ok := c.state.jsonPaths[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	var minRows uint64 = math.MaxUint64
+	for _, p := range paths {
+		c.state.jsonPaths[p] = struct{}{}
+		if rows := c.countDocument(data, nil); !found || rows < minRows {
+			path, minRows, found = p, rows, true
+		}
+		delete(c.state.jsonPaths, p)
+	}
+	return path, found
+}
+
+// countDocument 计算文档展开的行数
+func (c *dataEtl) countDocument(data interface{}, arrays map[string]int) uint64 {
+	if c.recordPath != nil {
+		return c.countRecords(data, "", nil, 0, arrays)
+	}
+	return c.countRows(data, "", nil, 0, arrays)
+}
+
+// countRows 计算文档展开的行数, 与 normalize 的展开规则保持一致, 但不会生成任何行
+// arrays 不为空时, 记录所有会产生多行的列表路径及其深度
+func (c *dataEtl) countRows(data interface{}, prefix string, path []string, depth int, arrays map[string]int) uint64 {
+	if _, ok := c.leaf(data); ok {
+		return 1
+	}
+	ref, _, cycle := c.visit(data, prefix)
+	if cycle {
+		return 1
+	}
+	if ref != nil {
+		defer c.leave(ref)
+	}
+	if data = indirect(data); data == nil {
+		return 1
+	}
+	if c.ignored(prefix, path) {
+		return 1
+	}
+	if _, ok := c.keepJSON(path); ok {
+		return 1
+	}
+	if c.maxDepth != Infinity && depth > c.maxDepth {
+		return 1
+	}
+	switch reflect.TypeOf(data).Kind() {
+	case reflect.Slice, reflect.Array:
+		s := reflect.ValueOf(data)
+		n := s.Len()
+		if n == 0 {
+			return 1
+		}
+		if c.explodeStrategy(path) == ExplodeIndex {
+			if c.maxIndexLen > 0 && n > c.maxIndexLen && c.maxIndexPolicy == LimitError {
+				return 1
+			}
+			fields, _ := c.indexFields(data, prefix, path)
+			return c.countFields(c.zipFields(fields, depth), depth, arrays)
+		}
+		if _, ok := c.state.jsonPaths[prefix]; ok {
+			return 1
+		}
+		if c.recordPath != nil && !c.recordPath.match(path) {
+			return 1
+		}
+		if c.maxArrayLen > 0 && n > c.maxArrayLen {
+			if c.maxArrayLenPolicy != LimitTruncate {
+				return 1
+			}
+			n = c.maxArrayLen
+		}
+		if c.explodeStrategy(path) == ExplodeFirst {
+			n = 1
+		}
+		var rows uint64
+		for i := 0; i < n; i++ {
+			rows = addRows(rows, c.countRows(s.Index(i).Interface(), prefix, appendPath(path, listElem), depth+1, arrays))
+		}
+		if arrays != nil && rows > 1 {
+			arrays[prefix] = depth
+		}
+		return rows
+	case reflect.Map:
+		return c.countFields(c.zipFields(c.mapFields(data, prefix, path), depth), depth, arrays)
+	case reflect.Struct:
+		return c.countFields(c.zipFields(c.structFields(data, prefix, path), depth), depth, arrays)
+	}
+	return 1
+}
+
+// countFields 计算对象展开的行数, 即所有复杂字段行数的乘积
+func (c *dataEtl) countFields(fields []field, depth int, arrays map[string]int) uint64 {
+	var rows uint64 = 1
+	for _, f := range fields {
+		if f.zip != nil {
+			rows = mulRows(rows, c.countZip(f.zip, depth, arrays))
+			continue
+		}
+		v := indirect(f.value)
+		if v == nil || f.json {
+			continue
+		}
+		if _, ok := c.leaf(f.value); ok {
+			continue
+		}
+		switch reflect.TypeOf(v).Kind() {
+		case reflect.Map, reflect.Slice, reflect.Struct, reflect.Array:
+			rows = mulRows(rows, c.countRows(f.value, f.prefix, f.path, depth+1, arrays))
+		}
+	}
+	return rows
+}
+
+func addRows(a, b uint64) uint64 {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64
+	}
+	return a + b
+}
+
+func mulRows(a, b uint64) uint64 {
+	if a != 0 && b > math.MaxUint64/a {
+		return math.MaxUint64
+	}
+	return a * b
+}
@@ -0,0 +1,121 @@
+package alt
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_dataEtl_limit(t *testing.T) {
+	var data = map[string]interface{}{
+		"aa": "a",
+		"bb": []int{1, 2},
+		"cc": []int{3, 4, 5},
+	}
+	tests := []struct {
+		name       string
+		opt        []OptionFunc
+		wantResult matrixKvPairs
+		wantRows   int
+		wantPath   string
+		wantErr    error
+	}{
+		{
+			name:     "max_rows_truncate",
+			opt:      []OptionFunc{SetMaxRows(4, LimitTruncate)},
+			wantRows: 4,
+		},
+		{
+			name:     "max_rows_error",
+			opt:      []OptionFunc{SetMaxRows(4, LimitError)},
+			wantPath: "cc",
+			wantErr:  ErrMaxRows,
+		},
+		{
+			name: "max_rows_json",
+			opt:  []OptionFunc{SetMaxRows(4, LimitJSON)},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "aa", Value: "a"}, {Key: "bb", Value: 1}, {Key: "cc", Value: "[3,4,5]"}},
+				[]pair{{Key: "aa", Value: "a"}, {Key: "bb", Value: 2}, {Key: "cc", Value: "[3,4,5]"}},
+			},
+		},
+		{
+			name: "max_rows_json_all",
+			opt:  []OptionFunc{SetMaxRows(1, LimitJSON)},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "aa", Value: "a"}, {Key: "bb", Value: "[1,2]"}, {Key: "cc", Value: "[3,4,5]"}},
+			},
+		},
+		{
+			// zip 展开的列表不会被序列化, 无法满足限制时返回错误而不是截断
+			name:    "max_rows_json_zip",
+			opt:     []OptionFunc{SetMaxRows(2, LimitJSON), SetExplode(ExplodeZip, "bb", "cc")},
+			wantErr: ErrMaxRows,
+		},
+		{
+			name: "max_array_len_truncate",
+			opt:  []OptionFunc{SetMaxArrayLen(1, LimitTruncate)},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "aa", Value: "a"}, {Key: "bb", Value: 1}, {Key: "cc", Value: 3}},
+			},
+		},
+		{
+			name:     "max_array_len_error",
+			opt:      []OptionFunc{SetMaxArrayLen(2, LimitError)},
+			wantPath: "cc",
+			wantErr:  ErrMaxArrayLen,
+		},
+		{
+			name: "max_array_len_json",
+			opt:  []OptionFunc{SetMaxArrayLen(2, LimitJSON)},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "aa", Value: "a"}, {Key: "bb", Value: 1}, {Key: "cc", Value: "[3,4,5]"}},
+				[]pair{{Key: "aa", Value: "a"}, {Key: "bb", Value: 2}, {Key: "cc", Value: "[3,4,5]"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := newTestParser(tt.opt...).ParseE(data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if pe, ok := err.(*ParseError); ok && pe.Path != tt.wantPath {
+				t.Errorf("ParseE() error path = %v, want %v", pe.Path, tt.wantPath)
+			}
+			if tt.wantRows > 0 && len(gotResult) != tt.wantRows {
+				t.Errorf("ParseE() rows = %d, want %d", len(gotResult), tt.wantRows)
+			}
+			if tt.wantResult != nil && !reflect.DeepEqual(covertHelper(gotResult), tt.wantResult) {
+				t.Errorf("ParseE() = %v, want %v", covertHelper(gotResult), tt.wantResult)
+			}
+		})
+	}
+}
+
+func Test_dataEtl_countRows(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+		want uint64
+	}{
+		{name: "primitive", data: 1, want: 1},
+		{name: "empty", data: []int{}, want: 1},
+		{name: "list", data: []int{1, 2, 3}, want: 3},
+		{name: "cartesian", data: map[string]interface{}{"a": []int{1, 2}, "b": []int{1, 2, 3}}, want: 6},
+		{name: "nested", data: []interface{}{1, map[string]interface{}{"a": []int{1, 2}, "b": nil}}, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestParser().(*dataEtl).fork()
+			var rows int
+			_ = c.ParseEach(tt.data, func(row map[string]interface{}) error {
+				rows++
+				return nil
+			})
+			if got := c.countRows(tt.data, "", nil, 0, nil); got != tt.want || int(got) != rows {
+				t.Errorf("countRows() = %v, want %v, parse rows %d", got, tt.want, rows)
+			}
+		})
+	}
+}
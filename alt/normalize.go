@@ -1,6 +1,7 @@
 package alt
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
@@ -12,14 +13,23 @@ const (
 
 type Parser interface {
 	Parse(data interface{}) (result []map[string]interface{})
+	ParseE(data interface{}) (result []map[string]interface{}, err error)
+	ParseEach(data interface{}, fn func(row map[string]interface{}) error) error
 }
 
 func NewDataEtlParser(opt ...OptionFunc) Parser {
 	v := &dataEtl{
-		maxDepth:  Infinity,
-		ignore:    make(map[string]struct{}),
-		logger:    NewStdLogger(LevelDebug, os.Stdout),
-		separator: StrSeparator("."),
+		maxDepth:    Infinity,
+		maxRows:     Infinity,
+		maxArrayLen: Infinity,
+		ignore:      make(map[string]struct{}),
+		logger:      NewStdLogger(LevelDebug, os.Stdout),
+		separator:   StrSeparator("."),
+		tagKey:      DefaultTagKey,
+		idKey:       DefaultIdKey,
+		parentIdKey: DefaultParentIdKey,
+		valueKey:    DefaultValueKey,
+		nextId:      counter(),
 	}
 	for _, f := range opt {
 		f(v)
@@ -53,6 +63,13 @@ func SetSeparator(separator Separator) OptionFunc {
 	}
 }
 
+// SetErrorMode 设置遇到异常数据时的处理方式, 默认为 ErrorModeSkip
+func SetErrorMode(mode ErrorMode) OptionFunc {
+	return func(c *dataEtl) {
+		c.errorMode = mode
+	}
+}
+
 var _ = NewDataEtlParser(
 	SetMaxDepth(Infinity),
 	SetLogger(NewStdLogger(LevelDebug, os.Stdout)),
@@ -65,65 +82,255 @@ type dataEtl struct {
 	logger    Logger
 	maxDepth  int
 	ignore    map[string]struct{}
+	errorMode ErrorMode
+
+	explode      ExplodeStrategy
+	explodeRules []explodeRule
+
+	maxIndexLen    int
+	maxIndexPolicy LimitPolicy
+
+	jsonRules []jsonRule
+
+	// SetIgnorePaths, SetInclude 设置的字段路径
+	ignorePaths  []pathSpec
+	includePaths []pathSpec
+
+	// 读取字段名的 struct tag
+	tagKey string
+
+	cyclePolicy CyclePolicy
+
+	// SetLeaf 注册的叶子类型
+	leaves map[reflect.Type]LeafFunc
+
+	transforms []transformRule
+
+	renames    []renameRule
+	renameFunc func(key string) string
+	collision  CollisionStrategy
+	normalizer *KeyNormalizer
+
+	// TableParser 输出的字段
+	tableColumns []string
+
+	deterministic bool
+
+	// SetRecordPath 设置的 record 路径和 meta 路径
+	recordPath pathSpec
+	metaPaths  []pathSpec
+
+	// RelationalParser 使用的字段名和 id 生成器
+	idKey       string
+	parentIdKey string
+	valueKey    string
+	nextId      func(table string) interface{}
+
+	maxRows           int
+	maxRowsPolicy     LimitPolicy
+	maxArrayLen       int
+	maxArrayLenPolicy LimitPolicy
+
+	// 单次解析的状态, 由 fork 创建
+	state *parseState
+}
+
+type parseState struct {
+	errs ParseErrors
+	seen map[string]struct{}
+	rows int
+	// 需要序列化为 JSON 的列表路径
+	jsonPaths map[string]struct{}
+	// 当前路径上正在展开的引用及其字段
+	visiting map[reference]string
+	// 输出的字段及其来源的字段路径, 为 nil 时不检查字段冲突
+	columns map[string][]string
+	// CollisionSuffix 为冲突的路径分配的字段名
+	suffixes map[string]string
+	// 规范化之后的字段名
+	names map[string]string
 }
 
+// fork 复制解析器的配置, 保证每次解析的状态互不影响
+func (c *dataEtl) fork() *dataEtl {
+	w := *c
+	w.state = &parseState{
+		seen:      make(map[string]struct{}),
+		jsonPaths: make(map[string]struct{}),
+		visiting:  make(map[reference]string),
+		columns:   make(map[string][]string),
+		suffixes:  make(map[string]string),
+		names:     make(map[string]string),
+	}
+	return &w
+}
+
+// Parse 解析数据, 忽略解析过程中的错误
 func (c *dataEtl) Parse(data interface{}) (result []map[string]interface{}) {
-	if data == nil {
+	result, _ = c.ParseE(data)
+	return result
+}
+
+// ParseE 解析数据, 根据 ErrorMode 返回解析过程中的错误
+func (c *dataEtl) ParseE(data interface{}) (result []map[string]interface{}, err error) {
+	result = make([]map[string]interface{}, 0)
+	err = c.ParseEach(data, func(row map[string]interface{}) error {
+		result = append(result, row)
+		return nil
+	})
+	if _, ok := err.(ParseErrors); err != nil && !ok {
+		return nil, err
+	}
+	return result, err
+}
+
+// ParseEach 流式解析数据, 每生成一行调用一次 fn, 不会在内存中保存完整的笛卡尔积
+// fn 返回 ErrStop 时提前结束解析, 返回其他错误时中断解析并返回该错误
+func (c *dataEtl) ParseEach(data interface{}, fn func(row map[string]interface{}) error) error {
+	if indirect(data) == nil {
 		c.logger.Debug("parser data is nil, return empty list")
-		return make([]map[string]interface{}, 0)
+		return nil
+	}
+	w := c.fork()
+	if err := w.checkRows(data); err != nil {
+		return err
+	}
+	err := w.parseDocument(data, func(row map[string]interface{}) error {
+		// 超过最大行数时截断
+		if w.maxRows > 0 && w.state.rows >= w.maxRows {
+			return ErrStop
+		}
+		w.state.rows++
+		return fn(cpm(row))
+	})
+	if err != nil && !errors.Is(err, ErrStop) {
+		return err
+	}
+	if len(w.state.errs) > 0 {
+		return w.state.errs
 	}
-	return c.normalize(data, "", make(map[string]interface{}), 0)
+	return nil
+}
+
+// parseDocument 解析一个文档, 设置了 record 路径时只输出 record
+func (c *dataEtl) parseDocument(data interface{}, emit emitFunc) error {
+	if c.recordPath != nil {
+		return c.parseRecord(data, "", nil, make(map[string]interface{}), 0, emit)
+	}
+	return c.normalize(data, "", nil, make(map[string]interface{}), 0, emit)
+}
+
+// report 根据 ErrorMode 处理解析错误, 只有 ErrorModeFailFast 会返回错误中断解析
+func (c *dataEtl) report(err *ParseError) error {
+	if c.errorMode == ErrorModeFailFast {
+		return err
+	}
+	// 同一个节点会因为笛卡尔积被多次遍历, 相同的错误只记录一次
+	if _, ok := c.state.seen[err.Error()]; ok {
+		return nil
+	}
+	c.state.seen[err.Error()] = struct{}{}
+	c.logger.Warn(err.Error())
+	if c.errorMode == ErrorModeCollect {
+		c.state.errs = append(c.state.errs, err)
+	}
+	return nil
+}
+
+// emitFunc 接收一行解析结果, 传入的 map 在调用之后不会再被修改
+type emitFunc func(row map[string]interface{}) error
+
+// field 对象中需要继续展开的字段
+type field struct {
+	key    interface{}
+	prefix string
+	path   []string
+	value  interface{}
+	// 按下标合并展开的同级列表
+	zip []field
+	// 合并展开时长度不足的列表, 字段值为 nil
+	pad bool
+	// 匿名嵌入的指针形成的循环引用, at 为引用第一次出现的字段
+	cycle bool
+	at    string
+	// struct tag 设置了保存为 JSON
+	json   bool
+	format JSONFormat
 }
 
 func (c *dataEtl) normalize(
 	data interface{},
 	prefix string,
+	path []string,
 	currentMap map[string]interface{},
 	depth int,
-) (result []map[string]interface{}) {
-	if data == nil {
+	emit emitFunc,
+) error {
+	ref, at, cycle := c.visit(data, prefix)
+	if cycle {
+		return c.parseCycle(data, prefix, path, at, currentMap, depth, emit)
+	}
+	if ref != nil {
+		defer c.leave(ref)
+		emit = c.pause(ref, prefix, emit)
+	}
+
+	raw := data
+	if data = indirect(data); data == nil {
 		ve := fmt.Sprintf("current key is %s, but value is <nil>", prefix)
 		c.logger.Debug(ve)
-		return []map[string]interface{}{cpm(currentMap)}
+		return emit(currentMap)
 	}
 
-	if _, find := c.ignore[prefix]; find {
+	if c.ignored(prefix, path) {
 		ig := fmt.Sprintf("key is ignored %s", prefix)
 		c.logger.Debug(ig)
-		return []map[string]interface{}{cpm(currentMap)}
+		return emit(currentMap)
+	}
+
+	if format, ok := c.keepJSON(path); ok {
+		return c.parseJSON(raw, prefix, path, currentMap, depth, format, emit)
 	}
 
 	if c.maxDepth != Infinity && depth > c.maxDepth {
-		msg := fmt.Sprintf("exclude depth %s, currentDepth:%d, maxDepth:%d", prefix, depth, c.maxDepth)
-		c.logger.Warn(msg)
-		return []map[string]interface{}{cpm(currentMap)}
+		if err := c.report(&ParseError{Path: prefix, Kind: reflect.TypeOf(data).Kind(), Depth: depth, Err: ErrMaxDepth}); err != nil {
+			return err
+		}
+		return emit(currentMap)
+	}
+
+	if v, ok := c.leaf(raw); ok {
+		return c.parseLeaf(v, prefix, path, currentMap, depth, emit)
 	}
 
-	c.logger.Debug(fmt.Sprintf("parser  type %v, data %v", reflect.TypeOf(data).Kind(), data))
+	// 循环引用的数据无法格式化, 只输出字段名
+	c.logger.Debug(fmt.Sprintf("parser  type %v, key %s", reflect.TypeOf(data).Kind(), prefix))
 	switch reflect.TypeOf(data).Kind() {
 	case
 		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
 		reflect.Bool, reflect.Uintptr, reflect.Float32, reflect.Float64, reflect.String:
-		return c.parsePrimitive(data, prefix, currentMap, depth)
+		return c.parsePrimitive(data, prefix, path, currentMap, depth, emit)
 
 	case reflect.Slice, reflect.Array:
-		return c.parseSlice(data, prefix, currentMap, depth)
+		return c.parseSlice(data, prefix, path, currentMap, depth, emit)
 
 	case reflect.Map:
 
-		return c.parseMap(data, prefix, currentMap, depth)
+		return c.parseMap(data, prefix, path, currentMap, depth, emit)
 
 	case reflect.Struct:
 
-		return c.parseStruct(data, prefix, currentMap, depth)
+		return c.parseStruct(data, prefix, path, currentMap, depth, emit)
 
 	default:
 
-		c.logger.Warn("normalize: unknown type ", reflect.TypeOf(data).Kind())
+		if err := c.report(&ParseError{Path: prefix, Kind: reflect.TypeOf(data).Kind(), Depth: depth, Err: ErrUnsupportedKind}); err != nil {
+			return err
+		}
 	}
 
-	return []map[string]interface{}{cpm(currentMap)}
+	return emit(currentMap)
 }
 
 // primitive  判断是否为基础类型
@@ -142,148 +349,280 @@ func (c *dataEtl) primitive(k reflect.Kind) bool {
 func (c *dataEtl) parsePrimitive(
 	data interface{},
 	prefix string,
+	path []string,
 	currentMap map[string]interface{},
 	depth int,
-) (result []map[string]interface{}) {
+	emit emitFunc,
+) error {
 	tmp := cpm(currentMap)
-	if _, ok := c.ignore[prefix]; !ok &&
+	if !c.ignored(prefix, path) &&
 		((c.maxDepth == Infinity) || (c.maxDepth != Infinity && c.maxDepth > depth)) &&
 		c.primitive(reflect.TypeOf(data).Kind()) {
 		c.logger.Debug(fmt.Sprintf("current type is %v, value is %v", reflect.TypeOf(data).Kind(), data))
-		tmp[prefix] = data
+		if err := c.setValue(tmp, prefix, data, prefix, path, depth); err != nil {
+			return err
+		}
+	}
+	return emit(tmp)
+}
+
+// parseLeaf 处理叶子类型, 转换之后的值直接填充到当前对象
+func (c *dataEtl) parseLeaf(
+	v interface{},
+	prefix string,
+	path []string,
+	currentMap map[string]interface{},
+	depth int,
+	emit emitFunc,
+) error {
+	if v == nil {
+		return emit(currentMap)
+	}
+	tmp := cpm(currentMap)
+	if err := c.setValue(tmp, prefix, v, prefix, path, depth); err != nil {
+		return err
 	}
-	return append(result, tmp)
+	return emit(tmp)
 }
 
-// 处理切片类型
+// 处理切片类型, 每个元素生成的行依次输出
 func (c *dataEtl) parseSlice(
 	data interface{},
 	prefix string,
+	path []string,
 	currentMap map[string]interface{},
 	depth int,
-) (result []map[string]interface{}) {
-	var newList []map[string]interface{}
+	emit emitFunc,
+) error {
 	var s = reflect.ValueOf(data)
 	c.logger.Debug(fmt.Sprintf("type is slice len is %d", s.Len()))
-	for i := 0; i < s.Len(); i++ {
-		var _res = c.normalize(s.Index(i).Interface(), prefix, cpm(currentMap), depth+1)
-		newList = append(newList, _res...)
+	// 如果列表为空, 输出 currentMap 对象本身
+	if s.Len() == 0 {
+		return emit(currentMap)
+	}
+	if c.explodeStrategy(path) == ExplodeIndex {
+		return c.parseIndex(data, prefix, path, currentMap, depth, emit)
 	}
-	// 如果列表为空, 返回 currentMap 对象本身的数组
-	if len(newList) == 0 {
-		newList = append(newList, cpm(currentMap))
+	// 设置了 record 路径时忽略其他的列表
+	if c.recordPath != nil && !c.recordPath.match(path) {
+		c.logger.Debug("record: list is ignored ", prefix)
+		return emit(currentMap)
 	}
-	return newList
+	if _, ok := c.state.jsonPaths[prefix]; ok {
+		return c.parseJSON(data, prefix, path, currentMap, depth, JSONString, emit)
+	}
+	var n = s.Len()
+	if c.maxArrayLen > 0 && n > c.maxArrayLen {
+		msg := fmt.Sprintf("max array len: %s has %d elements, more than %d", prefix, n, c.maxArrayLen)
+		c.logger.Warn(msg)
+		switch c.maxArrayLenPolicy {
+		case LimitError:
+			return &ParseError{Path: prefix, Kind: s.Kind(), Depth: depth, Err: ErrMaxArrayLen}
+		case LimitJSON:
+			return c.parseJSON(data, prefix, path, currentMap, depth, JSONString, emit)
+		default:
+			n = c.maxArrayLen
+		}
+	}
+	if c.explodeStrategy(path) == ExplodeFirst {
+		n = 1
+	}
+	for i := 0; i < n; i++ {
+		if err := c.normalize(s.Index(i).Interface(), prefix, appendPath(path, listElem), currentMap, depth+1, emit); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // 解析 map 类型
 func (c *dataEtl) parseMap(
 	data interface{},
 	prefix string,
+	path []string,
 	currentMap map[string]interface{},
 	depth int,
-) (result []map[string]interface{}) {
-	c.logger.Debug("type is map ", data)
-	var newList = []map[string]interface{}{cpm(currentMap)}
+	emit emitFunc,
+) error {
+	c.logger.Debug("type is map ", prefix)
 	if c.maxDepth != Infinity && depth > c.maxDepth {
 		c.logger.Debug(fmt.Sprintf("map: current depth:%d is more than maxDepth:%d", depth, c.maxDepth))
-		return newList
+		return emit(currentMap)
+	}
+	return c.parseObject(c.mapFields(data, prefix, path), currentMap, depth, emit)
+}
+
+// 解析 struct 类型
+func (c *dataEtl) parseStruct(
+	data interface{},
+	prefix string,
+	path []string,
+	currentMap map[string]interface{},
+	depth int,
+	emit emitFunc,
+) error {
+	c.logger.Debug("type is struct  ", prefix)
+	if c.maxDepth != Infinity && depth > c.maxDepth {
+		c.logger.Debug(fmt.Sprintf("struct: current depth:%d is more than maxDepth:%d", depth, c.maxDepth))
+		return emit(currentMap)
 	}
+	return c.parseObject(c.structFields(data, prefix, path), currentMap, depth, emit)
+}
+
+// mapFields 返回 map 中没有被忽略的字段
+func (c *dataEtl) mapFields(data interface{}, prefix string, path []string) (fields []field) {
 	for mr := reflect.ValueOf(data).MapRange(); mr.Next(); {
 		_key := mr.Key().Interface()
 		// 处理忽略键对象
-		if _, ok := c.ignore[c.separator.AppendToPrefix(prefix, _key)]; ok {
-			c.logger.Debug("map: current key is ignore %s", c.separator.AppendToPrefix(prefix, _key))
-			continue
+		f := field{
+			key:    _key,
+			prefix: c.appendKey(prefix, _key),
+			path:   appendPath(path, _key),
+			value:  mr.Value().Interface(),
 		}
-		_v := mr.Value().Interface()
-		c.logger.Debug(fmt.Sprintf("rec map key:%v, value:%v", _key, _v))
-		// NOTE 必须保证判断是有效的
-		// this case { "data": null }
-		if _v == nil {
-			c.logger.Warn(_key, " nil type ")
+		if c.skipField(f.prefix, f.path, f.value) {
+			c.logger.Debug("map: current key is ignore ", f.prefix)
 			continue
 		}
+		fields = append(fields, f)
+	}
+	if c.deterministic {
+		sortFields(fields)
+	}
+	return fields
+}
 
-		switch reflect.TypeOf(_v).Kind() {
-		case
-			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
-			reflect.Bool, reflect.Uintptr, reflect.Float32, reflect.Float64, reflect.String:
-			// 如果当前的值 数值，整型，布尔时，填充到所有已经遍历的对象
-			for i := range newList {
-				newList[i][c.separator.AppendToPrefix(prefix, _key)] = _v
-			}
-		case reflect.Map, reflect.Slice, reflect.Struct, reflect.Array:
-			var copyList = make([]map[string]interface{}, 0)
-			c.logger.Debug(fmt.Sprintf("type is %v", reflect.TypeOf(_v)))
-			for _, _v2 := range newList {
-				var _res = c.normalize(_v, c.separator.AppendToPrefix(prefix, _key), _v2, depth+1)
-				copyList = append(copyList, _res...)
-			}
-			newList = copyList
-		default:
-			c.logger.Warn("unknown type ", reflect.TypeOf(_v).Kind())
+// structFields 返回 struct 中没有被忽略的字段
+func (c *dataEtl) structFields(data interface{}, prefix string, path []string) (fields []field) {
+	for _, sf := range c.visibleFields(reflect.ValueOf(data), prefix) {
+		_key := sf.tag.name
+		f := field{
+			key:    _key,
+			prefix: c.appendKey(prefix, _key),
+			path:   appendPath(path, _key),
+			value:  sf.value,
+			cycle:  sf.cycle,
+			at:     sf.at,
+			json:   sf.tag.json,
+			format: sf.tag.format,
+		}
+		if c.skipField(f.prefix, f.path, f.value) {
+			c.logger.Debug("struct: current key is ignore ", f.prefix)
+			continue
 		}
+		fields = append(fields, f)
 	}
-	return newList
+	return fields
 }
 
-// 解析 struct 类型
-func (c *dataEtl) parseStruct(
-	data interface{},
-	prefix string,
+// parseObject 解析 map, struct 的字段
+// 基础类型的字段直接填充到当前对象, 复杂类型的字段交给 parseFields 展开
+func (c *dataEtl) parseObject(
+	fields []field,
 	currentMap map[string]interface{},
 	depth int,
-) (result []map[string]interface{}) {
-	c.logger.Debug("type is struct  ", data)
-	var newList = []map[string]interface{}{cpm(currentMap)}
-	if c.maxDepth != Infinity && depth > c.maxDepth {
-		c.logger.Debug(fmt.Sprintf("struct: current depth:%d is more than maxDepth:%d", depth, c.maxDepth))
-		return newList
-	}
-	rv := reflect.ValueOf(data)
-	rt := reflect.TypeOf(data)
-	for i := 0; i < rv.NumField(); i++ {
-		_key := rt.Field(i).Name
-		if _, ok := c.ignore[c.separator.AppendToPrefix(prefix, _key)]; ok {
-			c.logger.Debug("struct: current key is ignore %s", c.separator.AppendToPrefix(prefix, _key))
+	emit emitFunc,
+) error {
+	var tmp = cpm(currentMap)
+	var nested []field
+	for _, f := range fields {
+		c.logger.Debug(fmt.Sprintf("rec map key:%v, type:%T", f.key, f.value))
+		if f.cycle {
+			nested = append(nested, f)
 			continue
 		}
-		_v := rv.Field(i).Interface()
-		c.logger.Debug(fmt.Sprintf("rec map key:%v, value:%v", _key, _v))
 		// NOTE 必须保证判断是有效的
 		// this case { "data": null }
-		if _v == nil {
-			c.logger.Warn(_key, " nil type ")
+		v := indirect(f.value)
+		if v == nil {
+			c.logger.Warn(f.key, " nil type ")
+			continue
+		}
+
+		// 需要保存为 JSON 的字段交给 normalizeField 处理
+		if _, ok := c.fieldJSON(f); ok {
+			nested = append(nested, f)
+			continue
+		}
+
+		// 叶子类型转换之后填充到当前对象
+		if lv, ok := c.leaf(f.value); ok {
+			if lv == nil {
+				continue
+			}
+			if err := c.setValue(tmp, f.prefix, lv, f.prefix, f.path, depth+1); err != nil {
+				return err
+			}
 			continue
 		}
 
-		switch reflect.TypeOf(_v).Kind() {
+		switch reflect.TypeOf(v).Kind() {
 		case
 			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
 			reflect.Bool, reflect.Uintptr, reflect.Float32, reflect.Float64, reflect.String:
-			// 如果当前的值 数值，整型，布尔时，填充到所有已经遍历的对象
-			for i := range newList {
-				newList[i][c.separator.AppendToPrefix(prefix, _key)] = _v
+			// 如果当前的值 数值，整型，布尔时，填充到当前对象, 之后展开的行都会包含这个字段
+			if err := c.setValue(tmp, f.prefix, v, f.prefix, f.path, depth+1); err != nil {
+				return err
 			}
 		case reflect.Map, reflect.Slice, reflect.Struct, reflect.Array:
-			var copyList = make([]map[string]interface{}, 0)
-			c.logger.Debug(fmt.Sprintf("type is %v", reflect.TypeOf(_v)))
-			for _, _v2 := range newList {
-				var _res = c.normalize(_v, c.separator.AppendToPrefix(prefix, _key), _v2, depth+1)
-				copyList = append(copyList, _res...)
-			}
-			newList = copyList
+			c.logger.Debug(fmt.Sprintf("type is %v", reflect.TypeOf(v)))
+			nested = append(nested, f)
 			// 不支持的类型
-		case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128:
-			c.logger.Warn("unknown type i ", reflect.TypeOf(_v).Kind())
 		default:
-			c.logger.Warn("unknown type ", reflect.TypeOf(_v).Kind())
+			err := c.report(&ParseError{
+				Path:  f.prefix,
+				Kind:  reflect.TypeOf(v).Kind(),
+				Depth: depth + 1,
+				Err:   ErrUnsupportedKind,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return c.parseFields(c.zipFields(nested, depth), tmp, depth, emit)
+}
+
+// parseFields 依次展开对象中的复杂字段
+// 第一个字段展开的每一行, 再继续展开剩下的字段, 最终输出所有字段的笛卡尔积
+// 展开过程中只保存当前路径上的状态, 不会缓存已经生成的行
+func (c *dataEtl) parseFields(
+	fields []field,
+	currentMap map[string]interface{},
+	depth int,
+	emit emitFunc,
+) error {
+	if len(fields) == 0 {
+		return emit(currentMap)
+	}
+	if fields[0].zip != nil {
+		return c.parseZip(fields[0].zip, currentMap, depth, func(row map[string]interface{}) error {
+			return c.parseFields(fields[1:], row, depth, emit)
+		})
+	}
+	return c.normalizeField(fields[0], currentMap, depth+1, func(row map[string]interface{}) error {
+		return c.parseFields(fields[1:], row, depth, emit)
+	})
+}
+
+// normalizeField 展开对象的字段, struct tag 设置了保存为 JSON 的字段直接序列化
+func (c *dataEtl) normalizeField(f field, currentMap map[string]interface{}, depth int, emit emitFunc) error {
+	if f.pad {
+		var tmp = cpm(currentMap)
+		if err := c.put(tmp, f.prefix, f.path, nil, depth); err != nil {
+			return err
 		}
+		return emit(tmp)
+	}
+	if f.cycle {
+		return c.parseCycle(f.value, f.prefix, f.path, f.at, currentMap, depth, emit)
+	}
+
+	if f.json && indirect(f.value) != nil {
+		return c.parseJSON(f.value, f.prefix, f.path, currentMap, depth, f.format, emit)
 	}
-	return newList
+	return c.normalize(f.value, f.prefix, f.path, currentMap, depth, emit)
 }
 
 // copy map object
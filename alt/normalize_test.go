@@ -1,8 +1,10 @@
 package alt
 
 import (
+	"errors"
 	"os"
 	"reflect"
+	"sort"
 	"testing"
 )
 
@@ -259,3 +261,172 @@ func BenchmarkDataEtl_Parse_complex(b *testing.B) {
 		parser.Parse(cp)
 	}
 }
+
+func Test_dataEtl_ParseE(t *testing.T) {
+	var data = map[string]interface{}{
+		"aa": "a",
+		"bb": []interface{}{1, make(chan int)},
+		"cc": map[string]interface{}{
+			"dd": complex(1, 2),
+			"ee": map[string]interface{}{"ff": 1},
+		},
+	}
+	tests := []struct {
+		name       string
+		opt        []OptionFunc
+		wantResult matrixKvPairs
+		wantErr    bool
+		wantPaths  []string
+	}{
+		{
+			name: "skip",
+			opt:  []OptionFunc{SetErrorMode(ErrorModeSkip)},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "aa", Value: "a"}, {Key: "bb", Value: 1}, {Key: "cc.ee.ff", Value: 1}},
+				[]pair{{Key: "aa", Value: "a"}, {Key: "cc.ee.ff", Value: 1}},
+			},
+		},
+		{
+			name:    "fail_fast",
+			opt:     []OptionFunc{SetErrorMode(ErrorModeFailFast)},
+			wantErr: true,
+		},
+		{
+			name: "collect",
+			opt:  []OptionFunc{SetErrorMode(ErrorModeCollect)},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "aa", Value: "a"}, {Key: "bb", Value: 1}, {Key: "cc.ee.ff", Value: 1}},
+				[]pair{{Key: "aa", Value: "a"}, {Key: "cc.ee.ff", Value: 1}},
+			},
+			wantErr:   true,
+			wantPaths: []string{"bb", "cc.dd"},
+		},
+		{
+			name:      "collect_depth",
+			opt:       []OptionFunc{SetErrorMode(ErrorModeCollect), SetMaxDepth(1)},
+			wantErr:   true,
+			wantPaths: []string{"bb", "bb", "cc.dd", "cc.ee"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := append([]OptionFunc{SetLogger(NewStdLogger(LevelError, os.Stdout))}, tt.opt...)
+			gotResult, err := NewDataEtlParser(opt...).ParseE(data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantResult != nil && !reflect.DeepEqual(covertHelper(gotResult), tt.wantResult) {
+				t.Errorf("ParseE() = %v, want %v", covertHelper(gotResult), tt.wantResult)
+			}
+
+			switch e := err.(type) {
+			case *ParseError:
+				if gotResult != nil || !errors.Is(err, ErrUnsupportedKind) {
+					t.Errorf("ParseE() = %v, %v", gotResult, err)
+				}
+			case ParseErrors:
+				var paths []string
+				for _, pe := range e {
+					paths = append(paths, pe.Path)
+				}
+				sort.Strings(paths)
+				if !reflect.DeepEqual(paths, tt.wantPaths) {
+					t.Errorf("ParseE() error paths = %v, want %v", paths, tt.wantPaths)
+				}
+			}
+		})
+	}
+}
+
+func Test_dataEtl_ParseEach(t *testing.T) {
+	type item struct {
+		Id int
+	}
+	type doc struct {
+		Name  string
+		Tags  []string
+		Items []item
+	}
+	// struct 的字段有序, 靠前的字段先展开
+	var data = doc{Name: "n", Tags: []string{"a", "b"}, Items: []item{{Id: 1}, {Id: 2}, {Id: 3}}}
+	var all = []map[string]interface{}{
+		{"Name": "n", "Tags": "a", "Items.Id": 1},
+		{"Name": "n", "Tags": "a", "Items.Id": 2},
+		{"Name": "n", "Tags": "a", "Items.Id": 3},
+		{"Name": "n", "Tags": "b", "Items.Id": 1},
+		{"Name": "n", "Tags": "b", "Items.Id": 2},
+		{"Name": "n", "Tags": "b", "Items.Id": 3},
+	}
+	errBreak := errors.New("break")
+	tests := []struct {
+		name     string
+		stop     int
+		stopErr  error
+		wantRows []map[string]interface{}
+		wantErr  error
+	}{
+		{name: "all", stop: -1, wantRows: all},
+		{name: "stop", stop: 4, stopErr: ErrStop, wantRows: all[:4]},
+		{name: "break", stop: 1, stopErr: errBreak, wantRows: all[:1], wantErr: errBreak},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := newTestParser()
+			var rows []map[string]interface{}
+			err := parser.ParseEach(data, func(row map[string]interface{}) error {
+				rows = append(rows, row)
+				if len(rows) == tt.stop {
+					return tt.stopErr
+				}
+				return nil
+			})
+			if err != tt.wantErr {
+				t.Fatalf("ParseEach() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("ParseEach() = %v, want %v", rows, tt.wantRows)
+			}
+			if tt.stop < 0 && !reflect.DeepEqual(rows, parser.Parse(data)) {
+				t.Errorf("ParseEach() = %v, Parse() = %v", rows, parser.Parse(data))
+			}
+		})
+	}
+}
+
+func BenchmarkDataEtl_ParseEach_explode(b *testing.B) {
+	var l = make([]int, 1000)
+	for i := range l {
+		l[i] = i
+	}
+	var cp = map[string]interface{}{"l1": l, "l2": l, "l3": l}
+	parser := NewDataEtlParser(SetLogger(NewStdLogger(LevelWarn, os.Stdout)))
+	for i := 0; i < b.N; i++ {
+		var n = 0
+		_ = parser.ParseEach(cp, func(row map[string]interface{}) error {
+			if n++; n == 10000 {
+				return ErrStop
+			}
+			return nil
+		})
+	}
+}
+
+// newTestParser 返回只输出错误日志的解析器
+func newTestParser(opt ...OptionFunc) Parser {
+	return NewDataEtlParser(append([]OptionFunc{SetLogger(NewStdLogger(LevelError, os.Stdout))}, opt...)...)
+}
+
+// checkParse 比较解析输出的行, 同时检查 countDocument 预估的行数与输出的行数一致
+func checkParse(t *testing.T, parser Parser, data interface{}, wantResult matrixKvPairs) {
+	t.Helper()
+	gotResult, err := parser.ParseE(data)
+	if err != nil {
+		t.Fatalf("ParseE() error = %v", err)
+	}
+	if !reflect.DeepEqual(covertHelper(gotResult), wantResult) {
+		t.Errorf("ParseE() = %v, want %v", covertHelper(gotResult), wantResult)
+	}
+	if got := parser.(*dataEtl).fork().countDocument(data, nil); got != uint64(len(wantResult)) {
+		t.Errorf("countDocument() = %v, want %v", got, len(wantResult))
+	}
+}
@@ -0,0 +1,42 @@
+package alt
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// SetDeterministic 按字段名排序遍历 map, 相同的输入总是以相同的顺序输出相同的行
+// struct 总是按字段定义的顺序遍历, 默认按照 map 的遍历顺序, 行的顺序是随机的
+func SetDeterministic(deterministic bool) OptionFunc {
+	return func(c *dataEtl) {
+		c.deterministic = deterministic
+	}
+}
+
+// sortFields 按字段名排序 map 的字段
+func sortFields(fields []field) {
+	sort.SliceStable(fields, func(i, j int) bool {
+		return lessKey(fields[i].key, fields[j].key)
+	})
+}
+
+// lessKey 比较 map 的两个键, 字符串和数字按值比较, 其他类型按格式化之后的字符串比较
+func lessKey(a, b interface{}) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Kind() == vb.Kind() {
+		switch va.Kind() {
+		case reflect.String:
+			return va.String() < vb.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return va.Int() < vb.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return va.Uint() < vb.Uint()
+		case reflect.Float32, reflect.Float64:
+			return va.Float() < vb.Float()
+		case reflect.Bool:
+			return !va.Bool() && vb.Bool()
+		}
+	}
+	return fmt.Sprintf("%T:%v", a, a) < fmt.Sprintf("%T:%v", b, b)
+}
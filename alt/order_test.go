@@ -0,0 +1,60 @@
+package alt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_dataEtl_deterministic(t *testing.T) {
+	var data = map[string]interface{}{
+		"name": "map",
+		"data": []interface{}{
+			map[string]interface{}{"user_name": "小明", "age": 18},
+			map[string]interface{}{"user_name": "小海", "age": 17},
+		},
+		"data2": []interface{}{
+			map[string]interface{}{
+				"persons": []interface{}{map[string]interface{}{"address": "广东"}, map[string]interface{}{"address": "海南"}},
+			},
+		},
+		"ids": map[int]interface{}{10: []interface{}{"x", "y"}, 9: "z"},
+	}
+	want := []map[string]interface{}{
+		{"data.age": 18, "data.user_name": "小明", "data2.persons.address": "广东", "ids.10": "x", "ids.9": "z", "name": "map"},
+		{"data.age": 18, "data.user_name": "小明", "data2.persons.address": "广东", "ids.10": "y", "ids.9": "z", "name": "map"},
+		{"data.age": 18, "data.user_name": "小明", "data2.persons.address": "海南", "ids.10": "x", "ids.9": "z", "name": "map"},
+		{"data.age": 18, "data.user_name": "小明", "data2.persons.address": "海南", "ids.10": "y", "ids.9": "z", "name": "map"},
+		{"data.age": 17, "data.user_name": "小海", "data2.persons.address": "广东", "ids.10": "x", "ids.9": "z", "name": "map"},
+		{"data.age": 17, "data.user_name": "小海", "data2.persons.address": "广东", "ids.10": "y", "ids.9": "z", "name": "map"},
+		{"data.age": 17, "data.user_name": "小海", "data2.persons.address": "海南", "ids.10": "x", "ids.9": "z", "name": "map"},
+		{"data.age": 17, "data.user_name": "小海", "data2.persons.address": "海南", "ids.10": "y", "ids.9": "z", "name": "map"},
+	}
+	parser := newTestParser(SetDeterministic(true))
+	// 多次解析, 避免偶然得到相同的顺序
+	for i := 0; i < 20; i++ {
+		if got := parser.Parse(data); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Parse() = %v, want %v", got, want)
+		}
+	}
+}
+
+func Test_lessKey(t *testing.T) {
+	tests := []struct {
+		a, b interface{}
+		want bool
+	}{
+		{"a", "b", true},
+		{"b", "a", false},
+		{9, 10, true},
+		{uint(10), uint(9), false},
+		{1.5, 2.5, true},
+		{false, true, true},
+		{true, false, false},
+		{[2]int{1, 2}, [2]int{1, 3}, true},
+	}
+	for _, tt := range tests {
+		if got := lessKey(tt.a, tt.b); got != tt.want {
+			t.Errorf("lessKey(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package alt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pathSpec 字段路径, 所有按路径匹配字段的选项(SetExplode, SetKeepJSON, SetIgnorePaths 等)都使用这个格式
+// 与 Separator 无关, 使用 . 分隔每一级字段, 字段名中的 . 和 \ 使用 \ 转义, 例如 data2.persons, a\.b.c
+// * 匹配一级字段中的任意字符, 例如 data.*.secret, _*; ** 匹配任意多级字段, 例如 **._meta
+// 需要匹配字段名中的 * 时使用 \* 转义
+// 列表不会产生新的层级, 列表元素的路径与列表相同, 例如 data.secret 匹配 {"data": [{"secret": 1}]} 中的 secret,
+// 同时 * 也可以匹配列表元素的位置, 即 data.*.secret 也匹配该字段
+type pathSpec []string
+
+// listElem 列表元素在字段路径中的位置, 只能被 * 和 ** 匹配, 其他的字段名匹配时忽略
+const listElem = "\x00"
+
+// parsePathSpec 解析字段路径, 除了 \. 之外的转义会保留在字段名中, 由 matchSegment 处理
+func parsePathSpec(spec string) (p pathSpec) {
+	var seg strings.Builder
+	for i := 0; i < len(spec); i++ {
+		switch {
+		case spec[i] == '\\' && i+1 < len(spec):
+			i++
+			if spec[i] != '.' {
+				seg.WriteByte('\\')
+			}
+			seg.WriteByte(spec[i])
+		case spec[i] == '.':
+			p = append(p, seg.String())
+			seg.Reset()
+		default:
+			seg.WriteByte(spec[i])
+		}
+	}
+	return append(p, seg.String())
+}
+
+func parsePathSpecs(specs []string) []pathSpec {
+	var ps = make([]pathSpec, 0, len(specs))
+	for _, spec := range specs {
+		ps = append(ps, parsePathSpec(spec))
+	}
+	return ps
+}
+
+// match 判断字段路径是否与 pathSpec 匹配
+func (p pathSpec) match(path []string) bool {
+	if len(path) > 0 && path[0] == listElem {
+		if len(p) > 0 && p[0] == "*" && p[1:].match(path[1:]) {
+			return true
+		}
+		return p.match(path[1:])
+	}
+	if len(p) == 0 {
+		return len(path) == 0
+	}
+	if p[0] == "**" {
+		for i := 0; i <= len(path); i++ {
+			if p[1:].match(path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	return len(path) > 0 && matchSegment(p[0], path[0]) && p[1:].match(path[1:])
+}
+
+// hasPrefix 判断字段路径是否可能为匹配 pathSpec 的路径的前缀, 即该字段之下是否可能有匹配的字段
+func (p pathSpec) hasPrefix(path []string) bool {
+	if len(path) == 0 {
+		return true
+	}
+	if path[0] == listElem {
+		if len(p) > 0 && p[0] == "*" && p[1:].hasPrefix(path[1:]) {
+			return true
+		}
+		return p.hasPrefix(path[1:])
+	}
+	if len(p) == 0 {
+		return false
+	}
+	if p[0] == "**" {
+		return true
+	}
+	return matchSegment(p[0], path[0]) && p[1:].hasPrefix(path[1:])
+}
+
+// covers 判断字段路径或者它的某一级父字段是否与 pathSpec 匹配
+func (p pathSpec) covers(path []string) bool {
+	for i := len(path); i >= 0; i-- {
+		if p.match(path[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcard 判断 pathSpec 是否包含通配符
+func (p pathSpec) wildcard() bool {
+	for _, seg := range p {
+		for i := 0; i < len(seg); i++ {
+			if seg[i] == '\\' {
+				i++
+			} else if seg[i] == '*' {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String 返回 pathSpec 原始的格式
+func (p pathSpec) String() string {
+	var segs = make([]string, 0, len(p))
+	for _, seg := range p {
+		segs = append(segs, strings.Replace(seg, ".", `\.`, -1))
+	}
+	return strings.Join(segs, ".")
+}
+
+// matchSegment 判断字段名是否与一级路径匹配, * 匹配任意字符, \ 转义下一个字符
+func matchSegment(pattern, name string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(name); i >= 0; i-- {
+				if matchSegment(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+		}
+		if len(name) == 0 || name[0] != pattern[0] {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// appendPath 返回新的路径, 不会修改 path 底层的数组
+func appendPath(path []string, key interface{}) []string {
+	return append(path[:len(path):len(path)], fmt.Sprintf("%v", key))
+}
@@ -0,0 +1,116 @@
+package alt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parsePathSpec(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want pathSpec
+	}{
+		{name: "empty", spec: "", want: pathSpec{""}},
+		{name: "single", spec: "data", want: pathSpec{"data"}},
+		{name: "nested", spec: "data2.persons", want: pathSpec{"data2", "persons"}},
+		{name: "escape", spec: `a\.b.c\\d`, want: pathSpec{"a.b", `c\\d`}},
+		{name: "wildcard", spec: `**.*.\*`, want: pathSpec{"**", "*", `\*`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parsePathSpec(tt.spec); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePathSpec() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_pathSpec_match(t *testing.T) {
+	tests := []struct {
+		spec       string
+		path       []string
+		wantMatch  bool
+		wantPrefix bool
+		wantCovers bool
+	}{
+		{spec: "data.id", path: []string{"data", "id"}, wantMatch: true, wantPrefix: true, wantCovers: true},
+		{spec: "data.id", path: []string{"data"}, wantMatch: false, wantPrefix: true, wantCovers: false},
+		{spec: "data", path: []string{"data", "id"}, wantMatch: false, wantPrefix: false, wantCovers: true},
+		{spec: "data.*.secret", path: []string{"data", "x", "secret"}, wantMatch: true, wantPrefix: true, wantCovers: true},
+		{spec: "data.*.secret", path: []string{"data", "x"}, wantMatch: false, wantPrefix: true, wantCovers: false},
+		{spec: "data.*.secret", path: []string{"info", "x"}, wantMatch: false, wantPrefix: false, wantCovers: false},
+		{spec: "**._meta", path: []string{"_meta"}, wantMatch: true, wantPrefix: true, wantCovers: true},
+		{spec: "**._meta", path: []string{"a", "b", "_meta"}, wantMatch: true, wantPrefix: true, wantCovers: true},
+		{spec: "**._meta", path: []string{"a", "b"}, wantMatch: false, wantPrefix: true, wantCovers: false},
+		{spec: "**._meta", path: []string{"a", "_meta", "b"}, wantMatch: false, wantPrefix: true, wantCovers: true},
+		{spec: "a.**", path: []string{"a"}, wantMatch: true, wantPrefix: true, wantCovers: true},
+		{spec: "_*", path: []string{"_id"}, wantMatch: true, wantPrefix: true, wantCovers: true},
+		{spec: "*_id", path: []string{"parent_id"}, wantMatch: true, wantPrefix: true, wantCovers: true},
+		{spec: `\*`, path: []string{"a"}, wantMatch: false, wantPrefix: false, wantCovers: false},
+		{spec: `\*`, path: []string{"*"}, wantMatch: true, wantPrefix: true, wantCovers: true},
+		{spec: `c\\d`, path: []string{`c\d`}, wantMatch: true, wantPrefix: true, wantCovers: true},
+		{spec: "data.secret", path: []string{"data", listElem, "secret"}, wantMatch: true, wantPrefix: true, wantCovers: true},
+		{spec: "data.*.secret", path: []string{"data", listElem, "secret"}, wantMatch: true, wantPrefix: true, wantCovers: true},
+		{spec: "data.*.secret", path: []string{"data", listElem}, wantMatch: false, wantPrefix: true, wantCovers: false},
+		{spec: "data.*", path: []string{"data", listElem}, wantMatch: true, wantPrefix: true, wantCovers: true},
+		{spec: "data.*", path: []string{"data", listElem, "id"}, wantMatch: true, wantPrefix: true, wantCovers: true},
+		{spec: "data.*.*", path: []string{"data", listElem, "id"}, wantMatch: true, wantPrefix: true, wantCovers: true},
+		{spec: "data._*", path: []string{"data", listElem}, wantMatch: false, wantPrefix: true, wantCovers: false},
+		{spec: "**.secret", path: []string{"data", listElem, "secret"}, wantMatch: true, wantPrefix: true, wantCovers: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			p := parsePathSpec(tt.spec)
+			if got := p.match(tt.path); got != tt.wantMatch {
+				t.Errorf("match(%v) = %v, want %v", tt.path, got, tt.wantMatch)
+			}
+			if got := p.hasPrefix(tt.path); got != tt.wantPrefix {
+				t.Errorf("hasPrefix(%v) = %v, want %v", tt.path, got, tt.wantPrefix)
+			}
+			if got := p.covers(tt.path); got != tt.wantCovers {
+				t.Errorf("covers(%v) = %v, want %v", tt.path, got, tt.wantCovers)
+			}
+		})
+	}
+}
+
+func Test_pathSpec_String(t *testing.T) {
+	tests := []struct {
+		spec         string
+		wantWildcard bool
+	}{
+		{spec: "data2.persons", wantWildcard: false},
+		{spec: `a\.b.c\\d`, wantWildcard: false},
+		{spec: `\*.a`, wantWildcard: false},
+		{spec: "data.*.secret", wantWildcard: true},
+		{spec: "**._meta", wantWildcard: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			p := parsePathSpec(tt.spec)
+			if got := p.String(); got != tt.spec {
+				t.Errorf("String() = %v, want %v", got, tt.spec)
+			}
+			if got := p.wildcard(); got != tt.wantWildcard {
+				t.Errorf("wildcard() = %v, want %v", got, tt.wantWildcard)
+			}
+		})
+	}
+}
+
+func Test_formatPath(t *testing.T) {
+	if got := formatPath([]string{"a.b", `c\d`, "*"}); got != `a\.b.c\\d.\*` {
+		t.Errorf("formatPath() = %v", got)
+	}
+}
+
+func Test_appendPath(t *testing.T) {
+	var base = make([]string, 1, 4)
+	base[0] = "a"
+	p1 := appendPath(base, "b")
+	p2 := appendPath(base, 1)
+	if !reflect.DeepEqual(p1, []string{"a", "b"}) || !reflect.DeepEqual(p2, []string{"a", "1"}) {
+		t.Errorf("appendPath() = %v, %v", p1, p2)
+	}
+}
@@ -0,0 +1,195 @@
+package alt
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// SetRecordPath 只展开 record 路径上的列表, 类似 pandas.json_normalize 的 record_path 和 meta 参数
+// record 路径上的每个元素输出为一行, meta 路径上的值会复制到该对象下的每一行, 其他的列表都会被忽略
+// record 和 meta 为字段路径(格式见 pathSpec), 例如 SetRecordPath("data2.persons", "name", "data2.id")
+// 文档中没有 record 时不会输出任何行
+func SetRecordPath(record string, meta ...string) OptionFunc {
+	return func(c *dataEtl) {
+		c.recordPath = parsePathSpec(record)
+		c.metaPaths = parsePathSpecs(meta)
+	}
+}
+
+// recordRoute 判断路径是否在 record 路径上
+func (c *dataEtl) recordRoute(path []string) bool {
+	return c.recordPath.hasPrefix(path)
+}
+
+// metaRoute 判断路径是否在某个 meta 路径上, 以及是否就是 meta 路径
+func (c *dataEtl) metaRoute(path []string) (route, meta bool) {
+	for _, spec := range c.metaPaths {
+		if spec.match(path) {
+			return true, true
+		}
+		if spec.hasPrefix(path) {
+			route = true
+		}
+	}
+	return route, false
+}
+
+// parseRecord 沿着 record 路径查找 record, 同时收集经过的对象上的 meta 字段
+func (c *dataEtl) parseRecord(
+	data interface{},
+	prefix string,
+	path []string,
+	currentMap map[string]interface{},
+	depth int,
+	emit emitFunc,
+) error {
+	raw := data
+	if data = indirect(data); data == nil {
+		return nil
+	}
+	if c.ignored(prefix, path) {
+		c.logger.Debug("record: key is ignored ", prefix)
+		return nil
+	}
+	if c.maxDepth != Infinity && depth > c.maxDepth {
+		return c.report(&ParseError{Path: prefix, Kind: reflect.TypeOf(data).Kind(), Depth: depth, Err: ErrMaxDepth})
+	}
+
+	rv := reflect.ValueOf(data)
+	// 到达 record 路径, 列表中的每个元素都是一个 record
+	if c.recordPath.match(path) {
+		if (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Len() == 0 {
+			return nil
+		}
+		return c.normalize(raw, prefix, path, currentMap, depth, emit)
+	}
+
+	ref, at, cycle := c.visit(raw, prefix)
+	if cycle {
+		_, err := c.cycleValue(data, prefix, at, depth)
+		return err
+	}
+	if ref != nil {
+		defer c.leave(ref)
+		emit = c.pause(ref, prefix, emit)
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i, n := 0, c.arrayLen(rv); i < n; i++ {
+			if err := c.parseRecord(rv.Index(i).Interface(), prefix, appendPath(path, listElem), currentMap, depth+1, emit); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map, reflect.Struct:
+		tmp, routes, err := c.parseMeta(data, prefix, path, currentMap, depth)
+		if err != nil {
+			return err
+		}
+		// 路径中有通配符时可能有多个 record
+		for _, route := range routes {
+			if err := c.parseRecord(route.value, route.prefix, route.path, tmp, depth+1, emit); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	c.logger.Debug(fmt.Sprintf("record: %s is %v, record path not found", prefix, rv.Kind()))
+	return nil
+}
+
+// parseMeta 收集对象中的 meta 字段, 返回 record 路径上的下一级字段
+func (c *dataEtl) parseMeta(
+	data interface{},
+	prefix string,
+	path []string,
+	currentMap map[string]interface{},
+	depth int,
+) (tmp map[string]interface{}, routes []field, err error) {
+	var fields []field
+	if reflect.TypeOf(data).Kind() == reflect.Map {
+		fields = c.mapFields(data, prefix, path)
+	} else {
+		fields = c.structFields(data, prefix, path)
+	}
+	tmp = currentMap
+	for _, f := range fields {
+		if c.recordRoute(f.path) {
+			routes = append(routes, f)
+			continue
+		}
+		v := indirect(f.value)
+		if v == nil {
+			continue
+		}
+		switch onRoute, isMeta := c.metaRoute(f.path); {
+		case isMeta:
+			// meta 对象中的列表会被忽略, 所以只会生成一行
+			err = c.normalizeField(f, tmp, depth+1, func(row map[string]interface{}) error {
+				tmp = row
+				return nil
+			})
+		case onRoute:
+			if k := reflect.TypeOf(v).Kind(); k == reflect.Map || k == reflect.Struct {
+				tmp, _, err = c.parseMeta(v, f.prefix, f.path, tmp, depth+1)
+			} else {
+				c.logger.Debug(fmt.Sprintf("record: meta %s is %v, not an object", f.prefix, k))
+			}
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return tmp, routes, nil
+}
+
+// countRecords 计算文档中 record 的个数, 与 parseRecord 的规则保持一致
+func (c *dataEtl) countRecords(data interface{}, prefix string, path []string, depth int, arrays map[string]int) uint64 {
+	raw := data
+	if data = indirect(data); data == nil {
+		return 0
+	}
+	if c.ignored(prefix, path) {
+		return 0
+	}
+	if c.maxDepth != Infinity && depth > c.maxDepth {
+		return 0
+	}
+	rv := reflect.ValueOf(data)
+	if c.recordPath.match(path) {
+		if (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Len() == 0 {
+			return 0
+		}
+		return c.countRows(raw, prefix, path, depth, arrays)
+	}
+	ref, _, cycle := c.visit(raw, prefix)
+	if cycle {
+		return 0
+	}
+	if ref != nil {
+		defer c.leave(ref)
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		var rows uint64
+		for i, n := 0, c.arrayLen(rv); i < n; i++ {
+			rows = addRows(rows, c.countRecords(rv.Index(i).Interface(), prefix, appendPath(path, listElem), depth+1, arrays))
+		}
+		return rows
+	case reflect.Map, reflect.Struct:
+		var fields []field
+		if rv.Kind() == reflect.Map {
+			fields = c.mapFields(data, prefix, path)
+		} else {
+			fields = c.structFields(data, prefix, path)
+		}
+		var rows uint64
+		for _, f := range fields {
+			if c.recordRoute(f.path) {
+				rows = addRows(rows, c.countRecords(f.value, f.prefix, f.path, depth+1, arrays))
+			}
+		}
+		return rows
+	}
+	return 0
+}
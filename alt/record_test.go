@@ -0,0 +1,83 @@
+package alt
+
+import (
+	"testing"
+)
+
+func Test_dataEtl_recordPath(t *testing.T) {
+	var data = map[string]interface{}{
+		"name": "map",
+		"info": map[string]interface{}{"governor": "小明", "tags": []string{"a", "b"}},
+		"data": []interface{}{
+			map[string]interface{}{"user_name": "小明", "age": 18},
+			map[string]interface{}{"user_name": "小海", "age": 17},
+		},
+		"data2": []interface{}{
+			map[string]interface{}{
+				"id": 1,
+				"persons": []interface{}{
+					map[string]interface{}{"address": "广东", "phones": []int{1, 2}},
+					map[string]interface{}{"address": "海南"},
+				},
+			},
+			map[string]interface{}{
+				"id":      2,
+				"persons": []interface{}{map[string]interface{}{"address": "北京"}},
+			},
+			map[string]interface{}{
+				"id":      3,
+				"persons": []interface{}{},
+			},
+		},
+	}
+	tests := []struct {
+		name       string
+		opt        []OptionFunc
+		wantResult matrixKvPairs
+	}{
+		{
+			name: "record",
+			opt:  []OptionFunc{SetRecordPath("data2.persons")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "data2.persons.address", Value: "北京"}},
+				[]pair{{Key: "data2.persons.address", Value: "广东"}},
+				[]pair{{Key: "data2.persons.address", Value: "海南"}},
+			},
+		},
+		{
+			name: "record_meta",
+			opt:  []OptionFunc{SetRecordPath("data2.persons", "name", "data2.id", "info")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "data2.id", Value: 1}, {Key: "data2.persons.address", Value: "广东"}, {Key: "info.governor", Value: "小明"}, {Key: "name", Value: "map"}},
+				[]pair{{Key: "data2.id", Value: 1}, {Key: "data2.persons.address", Value: "海南"}, {Key: "info.governor", Value: "小明"}, {Key: "name", Value: "map"}},
+				[]pair{{Key: "data2.id", Value: 2}, {Key: "data2.persons.address", Value: "北京"}, {Key: "info.governor", Value: "小明"}, {Key: "name", Value: "map"}},
+			},
+		},
+		{
+			name: "record_top_list",
+			opt:  []OptionFunc{SetRecordPath("data", "name"), SetSeparator(StrSeparator("_"))},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "data_age", Value: 17}, {Key: "data_user_name", Value: "小海"}, {Key: "name", Value: "map"}},
+				[]pair{{Key: "data_age", Value: 18}, {Key: "data_user_name", Value: "小明"}, {Key: "name", Value: "map"}},
+			},
+		},
+		{
+			name: "record_wildcard",
+			opt:  []OptionFunc{SetRecordPath("*.persons", "data2.id")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "data2.id", Value: 1}, {Key: "data2.persons.address", Value: "广东"}},
+				[]pair{{Key: "data2.id", Value: 1}, {Key: "data2.persons.address", Value: "海南"}},
+				[]pair{{Key: "data2.id", Value: 2}, {Key: "data2.persons.address", Value: "北京"}},
+			},
+		},
+		{
+			name: "record_not_found",
+			opt:  []OptionFunc{SetRecordPath("data3", "name")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkParse(t, newTestParser(tt.opt...), data, tt.wantResult)
+		})
+	}
+}
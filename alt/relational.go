@@ -0,0 +1,309 @@
+package alt
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+)
+
+const (
+	// RootTable 根对象对应的表名
+	RootTable = "root"
+
+	DefaultIdKey       = "_id"
+	DefaultParentIdKey = "_parent_id"
+	DefaultValueKey    = "_value"
+)
+
+// RelationalParser 将文档解析为多张关联的表
+// 根对象输出到 RootTable, 每个列表路径输出到一张以列表路径命名的表, 例如 data, data2.persons
+// 每一行都有生成的 id, 子表的行通过 parent id 关联到父表的行
+type RelationalParser interface {
+	ParseTables(data interface{}) (tables map[string][]map[string]interface{}, err error)
+}
+
+func NewRelationalParser(opt ...OptionFunc) RelationalParser {
+	return NewDataEtlParser(opt...).(*dataEtl)
+}
+
+// SetTableKeys 设置 id, parent id 以及基础类型列表元素的字段名, 为空的字段名使用默认值
+func SetTableKeys(idKey, parentIdKey, valueKey string) OptionFunc {
+	return func(c *dataEtl) {
+		c.idKey = idKey
+		c.parentIdKey = parentIdKey
+		c.valueKey = valueKey
+	}
+}
+
+// SetIdGenerator 设置生成行 id 的函数, 默认为解析器内递增的整数
+func SetIdGenerator(nextId func(table string) interface{}) OptionFunc {
+	return func(c *dataEtl) {
+		c.nextId = nextId
+	}
+}
+
+// counter 返回一个并发安全的递增 id 生成器
+func counter() func(table string) interface{} {
+	var id int64
+	return func(table string) interface{} {
+		return atomic.AddInt64(&id, 1)
+	}
+}
+
+func (c *dataEtl) ParseTables(data interface{}) (tables map[string][]map[string]interface{}, err error) {
+	tables = make(map[string][]map[string]interface{})
+	if indirect(data) == nil {
+		c.logger.Debug("parser data is nil, return empty tables")
+		return tables, nil
+	}
+	w := c.fork()
+	// 不同表的字段名相互独立, 不检查字段冲突
+	w.state.columns = nil
+	data = indirect(data)
+	if w.nextId == nil {
+		w.nextId = counter()
+	}
+	if w.idKey == "" {
+		w.idKey = DefaultIdKey
+	}
+	if w.parentIdKey == "" {
+		w.parentIdKey = DefaultParentIdKey
+	}
+	if w.valueKey == "" {
+		w.valueKey = DefaultValueKey
+	}
+
+	if k := reflect.TypeOf(data).Kind(); k == reflect.Slice || k == reflect.Array {
+		// 根对象为列表时, 每个元素都是根表的一行
+		err = w.parseTableSlice(data, RootTable, "", nil, nil, 0, tables)
+	} else {
+		root := map[string]interface{}{w.idKey: w.nextId(RootTable)}
+		tables[RootTable] = append(tables[RootTable], root)
+		err = w.parseTableValue(data, "", "", nil, root, 0, tables)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(w.state.errs) > 0 {
+		return tables, w.state.errs
+	}
+	return tables, nil
+}
+
+// parseTableValue 将值填充到当前表的行, column 为相对当前表的字段名
+// 对象的字段继续填充到当前行, 列表输出到子表
+func (c *dataEtl) parseTableValue(
+	data interface{},
+	column string,
+	prefix string,
+	path []string,
+	row map[string]interface{},
+	depth int,
+	tables map[string][]map[string]interface{},
+) error {
+	ref, at, cycle := c.visit(data, prefix)
+	if cycle {
+		v, err := c.cycleValue(data, prefix, at, depth)
+		if v == nil || err != nil {
+			return err
+		}
+		return c.put(row, c.column(column), path, v, depth)
+	}
+	if ref != nil {
+		defer c.leave(ref)
+	}
+	raw := data
+	if data = indirect(data); data == nil {
+		return nil
+	}
+	if c.ignored(prefix, path) {
+		c.logger.Debug("table: key is ignored ", prefix)
+		return nil
+	}
+	if format, ok := c.keepJSON(path); ok {
+		return c.parseTableJSON(raw, column, prefix, path, row, depth, format)
+	}
+	if c.maxDepth != Infinity && depth > c.maxDepth {
+		return c.report(&ParseError{Path: prefix, Kind: reflect.TypeOf(data).Kind(), Depth: depth, Err: ErrMaxDepth})
+	}
+	if v, ok := c.leaf(raw); ok {
+		if v == nil {
+			return nil
+		}
+		return c.setValue(row, c.column(column), v, prefix, path, depth)
+	}
+
+	switch k := reflect.TypeOf(data).Kind(); {
+	case c.primitive(k):
+		return c.setValue(row, c.column(column), data, prefix, path, depth)
+
+	case k == reflect.Slice || k == reflect.Array:
+		if c.explodeStrategy(path) == ExplodeIndex {
+			return c.parseTableIndex(data, column, prefix, path, row, depth, tables)
+		}
+		s := reflect.ValueOf(data)
+		if c.maxArrayLen > 0 && s.Len() > c.maxArrayLen {
+			c.logger.Warn(fmt.Sprintf("max array len: %s has %d elements, more than %d", prefix, s.Len(), c.maxArrayLen))
+			switch c.maxArrayLenPolicy {
+			case LimitError:
+				return &ParseError{Path: prefix, Kind: k, Depth: depth, Err: ErrMaxArrayLen}
+			case LimitJSON:
+				return c.parseTableJSON(data, column, prefix, path, row, depth, JSONString)
+			}
+		}
+		return c.parseTableSlice(data, prefix, prefix, path, row[c.idKey], depth, tables)
+
+	case k == reflect.Map:
+		return c.parseTableFields(c.mapFields(data, prefix, path), column, row, depth, tables)
+
+	case k == reflect.Struct:
+		return c.parseTableFields(c.structFields(data, prefix, path), column, row, depth, tables)
+	}
+	return c.report(&ParseError{Path: prefix, Kind: reflect.TypeOf(data).Kind(), Depth: depth, Err: ErrUnsupportedKind})
+}
+
+// parseTableJSON 将字段序列化为 JSON 填充到当前行
+func (c *dataEtl) parseTableJSON(
+	data interface{},
+	column string,
+	prefix string,
+	path []string,
+	row map[string]interface{},
+	depth int,
+	format JSONFormat,
+) error {
+	v, err := c.marshalValue(data, prefix, depth, format)
+	if v == nil || err != nil {
+		return err
+	}
+	return c.put(row, c.column(column), path, v, depth)
+}
+
+// parseTableIndex 将列表以下标命名的字段填充到当前行
+func (c *dataEtl) parseTableIndex(
+	data interface{},
+	column string,
+	prefix string,
+	path []string,
+	row map[string]interface{},
+	depth int,
+	tables map[string][]map[string]interface{},
+) error {
+	s := reflect.ValueOf(data)
+	if c.maxIndexLen > 0 && s.Len() > c.maxIndexLen {
+		c.logger.Warn(fmt.Sprintf("max index: %s has %d elements, more than max index %d", prefix, s.Len(), c.maxIndexLen-1))
+		if c.maxIndexPolicy == LimitError {
+			return &ParseError{Path: prefix, Kind: s.Kind(), Depth: depth, Err: ErrMaxIndex}
+		}
+	}
+	fields, rest := c.indexFields(data, prefix, path)
+	if rest != nil {
+		err := c.parseTableJSON(rest, c.separator.AppendToPrefix(column, IndexRestKey), prefix, appendPath(path, IndexRestKey), row, depth, JSONString)
+		if err != nil {
+			return err
+		}
+	}
+	return c.parseTableFields(fields, column, row, depth, tables)
+}
+
+// parseTableFields 将对象的字段填充到当前行
+func (c *dataEtl) parseTableFields(
+	fields []field,
+	column string,
+	row map[string]interface{},
+	depth int,
+	tables map[string][]map[string]interface{},
+) error {
+	for _, f := range fields {
+		if f.cycle {
+			v, err := c.cycleValue(f.value, f.prefix, f.at, depth+1)
+			if err != nil {
+				return err
+			}
+			if v != nil {
+				if err := c.put(row, c.appendKey(column, f.key), f.path, v, depth+1); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if f.json && indirect(f.value) != nil {
+			if err := c.parseTableJSON(f.value, c.appendKey(column, f.key), f.prefix, f.path, row, depth+1, f.format); err != nil {
+				return err
+			}
+			continue
+		}
+		err := c.parseTableValue(f.value, c.appendKey(column, f.key), f.prefix, f.path, row, depth+1, tables)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// column 基础类型的列表元素没有字段名, 使用 valueKey 作为字段名
+func (c *dataEtl) column(column string) string {
+	if column == "" {
+		return c.valueKey
+	}
+	return column
+}
+
+// parseTableSlice 将列表的每个元素输出为子表的一行, 列表中嵌套的列表输出到同一张表
+func (c *dataEtl) parseTableSlice(
+	data interface{},
+	table string,
+	prefix string,
+	path []string,
+	parentId interface{},
+	depth int,
+	tables map[string][]map[string]interface{},
+) error {
+	s := reflect.ValueOf(data)
+	n := c.arrayLen(s)
+	if n > 0 && c.explodeStrategy(path) == ExplodeFirst {
+		n = 1
+	}
+	for i := 0; i < n; i++ {
+		v, elemPath := s.Index(i).Interface(), appendPath(path, listElem)
+		// 叶子类型的列表, 例如 []byte, 不是嵌套的列表
+		if _, leaf := c.leaf(v); !leaf && indirect(v) != nil {
+			if k := reflect.TypeOf(indirect(v)).Kind(); k == reflect.Slice || k == reflect.Array {
+				if err := c.parseTableNested(v, table, prefix, elemPath, parentId, depth+1, tables); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		row := map[string]interface{}{c.idKey: c.nextId(table)}
+		if parentId != nil {
+			row[c.parentIdKey] = parentId
+		}
+		tables[table] = append(tables[table], row)
+		if err := c.parseTableValue(v, "", prefix, elemPath, row, depth+1, tables); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTableNested 列表中嵌套的列表输出到同一张表
+func (c *dataEtl) parseTableNested(
+	data interface{},
+	table string,
+	prefix string,
+	path []string,
+	parentId interface{},
+	depth int,
+	tables map[string][]map[string]interface{},
+) error {
+	ref, at, cycle := c.visit(data, prefix)
+	if cycle {
+		_, err := c.cycleValue(data, prefix, at, depth)
+		return err
+	}
+	if ref != nil {
+		defer c.leave(ref)
+	}
+	return c.parseTableSlice(indirect(data), table, prefix, path, parentId, depth, tables)
+}
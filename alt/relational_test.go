@@ -0,0 +1,116 @@
+package alt
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_dataEtl_ParseTables(t *testing.T) {
+	// 每张表独立递增的 id, 保证测试结果稳定
+	tableCounter := func() func(table string) interface{} {
+		var ids = make(map[string]int)
+		return func(table string) interface{} {
+			ids[table]++
+			return ids[table]
+		}
+	}
+	tests := []struct {
+		name       string
+		data       interface{}
+		opt        []OptionFunc
+		wantTables map[string][]map[string]interface{}
+	}{
+		{
+			name: "readme",
+			data: map[string]interface{}{
+				"name": "map",
+				"data": []interface{}{
+					map[string]interface{}{"user_name": "小明", "age": 18, "info": map[string]interface{}{"province": "广东"}},
+					map[string]interface{}{"user_name": "小海", "age": 17, "info": map[string]interface{}{"province": "海南"}},
+				},
+				"data2": []interface{}{
+					map[string]interface{}{
+						"persons": []interface{}{
+							map[string]interface{}{"address": "广东"},
+							map[string]interface{}{"address": "海南"},
+						},
+					},
+				},
+			},
+			wantTables: map[string][]map[string]interface{}{
+				"root": {
+					{"_id": 1, "name": "map"},
+				},
+				"data": {
+					{"_id": 1, "_parent_id": 1, "user_name": "小明", "age": 18, "info.province": "广东"},
+					{"_id": 2, "_parent_id": 1, "user_name": "小海", "age": 17, "info.province": "海南"},
+				},
+				"data2": {
+					{"_id": 1, "_parent_id": 1},
+				},
+				"data2.persons": {
+					{"_id": 1, "_parent_id": 1, "address": "广东"},
+					{"_id": 2, "_parent_id": 1, "address": "海南"},
+				},
+			},
+		},
+		{
+			name: "root_list",
+			data: []interface{}{
+				map[string]interface{}{"tags": []interface{}{"a", []string{"b", "c"}}},
+				1,
+			},
+			opt: []OptionFunc{SetTableKeys("id", "pid", "value")},
+			wantTables: map[string][]map[string]interface{}{
+				"root": {
+					{"id": 1},
+					{"id": 2, "value": 1},
+				},
+				"tags": {
+					{"id": 1, "pid": 1, "value": "a"},
+					{"id": 2, "pid": 1, "value": "b"},
+					{"id": 3, "pid": 1, "value": "c"},
+				},
+			},
+		},
+		{
+			name: "default_keys",
+			data: map[string]interface{}{"tags": []interface{}{"a"}},
+			opt:  []OptionFunc{SetTableKeys("", "pid", "")},
+			wantTables: map[string][]map[string]interface{}{
+				"root": {
+					{"_id": 1},
+				},
+				"tags": {
+					{"_id": 1, "pid": 1, "_value": "a"},
+				},
+			},
+		},
+		{
+			name: "index_rest",
+			data: map[string]interface{}{"tags": []interface{}{"a", "b", "c"}},
+			opt:  []OptionFunc{SetExplode(ExplodeIndex, "tags"), SetMaxIndex(0, LimitJSON)},
+			wantTables: map[string][]map[string]interface{}{
+				"root": {
+					{"_id": 1, "tags.0": "a", "tags.rest": `["b","c"]`},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := append([]OptionFunc{
+				SetLogger(NewStdLogger(LevelError, os.Stdout)),
+				SetIdGenerator(tableCounter()),
+			}, tt.opt...)
+			gotTables, err := NewRelationalParser(opt...).ParseTables(tt.data)
+			if err != nil {
+				t.Fatalf("ParseTables() error = %v", err)
+			}
+			if !reflect.DeepEqual(gotTables, tt.wantTables) {
+				t.Errorf("ParseTables() = %v, want %v", gotTables, tt.wantTables)
+			}
+		})
+	}
+}
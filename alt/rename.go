@@ -0,0 +1,104 @@
+package alt
+
+import (
+	"sort"
+	"strings"
+)
+
+type renameRule struct {
+	spec   pathSpec
+	target string
+}
+
+// SetRename 将字段重命名为目标字段名, key 为字段路径(格式见 pathSpec), 支持通配符
+// 例如 SetRename(map[string]string{"data2.persons.address": "address", "**._id": "id"})
+// 多个规则匹配时, 没有通配符的规则优先, 其次按字段路径的字典序
+func SetRename(rename map[string]string) OptionFunc {
+	return func(c *dataEtl) {
+		for spec, target := range rename {
+			c.renames = append(c.renames, renameRule{spec: parsePathSpec(spec), target: target})
+		}
+		sort.SliceStable(c.renames, func(i, j int) bool {
+			wi, wj := c.renames[i].spec.wildcard(), c.renames[j].spec.wildcard()
+			if wi != wj {
+				return wj
+			}
+			return c.renames[i].spec.String() < c.renames[j].spec.String()
+		})
+	}
+}
+
+// SetRenameFunc 设置重命名函数, 参数为扁平化之后的字段名, 返回空字符串时不修改, 在 SetRename 没有匹配时调用
+func SetRenameFunc(fn func(key string) string) OptionFunc {
+	return func(c *dataEtl) {
+		c.renameFunc = fn
+	}
+}
+
+// rename 返回字段重命名之后的字段名
+func (c *dataEtl) rename(key string, path []string) string {
+	for _, rule := range c.renames {
+		if rule.spec.match(path) {
+			return rule.target
+		}
+	}
+	if c.renameFunc != nil {
+		if target := c.renameFunc(key); target != "" {
+			return target
+		}
+	}
+	return key
+}
+
+// put 将值填充到 row 中重命名并规范化之后的字段, 不同路径的字段写入同一个字段时根据 CollisionStrategy 处理
+func (c *dataEtl) put(row map[string]interface{}, key string, path []string, v interface{}, depth int) error {
+	target := c.normalizeKey(c.rename(key, path))
+	if c.state.columns != nil {
+		if prev, ok := c.state.columns[target]; !ok {
+			c.state.columns[target] = path
+		} else if !samePath(prev, path) {
+			var err error
+			if target, err = c.resolveCollision(target, prev, path, v, depth); err != nil || target == "" {
+				return err
+			}
+		}
+	}
+	row[target] = v
+	return nil
+}
+
+// samePath 判断两个字段路径是否相同, 忽略列表元素的位置
+func samePath(a, b []string) bool {
+	a, b = trimListElem(a), trimListElem(b)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+var pathEscaper = strings.NewReplacer(`\`, `\\`, `.`, `\.`, `*`, `\*`)
+
+// formatPath 将字段路径格式化为 pathSpec 的格式, 用于日志和错误信息
+func formatPath(path []string) string {
+	var segs = make([]string, 0, len(path))
+	for _, seg := range trimListElem(path) {
+		segs = append(segs, pathEscaper.Replace(seg))
+	}
+	return strings.Join(segs, ".")
+}
+
+// trimListElem 返回去掉列表元素位置的字段路径
+func trimListElem(path []string) []string {
+	var trimmed = make([]string, 0, len(path))
+	for _, seg := range path {
+		if seg != listElem {
+			trimmed = append(trimmed, seg)
+		}
+	}
+	return trimmed
+}
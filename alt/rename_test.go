@@ -0,0 +1,90 @@
+package alt
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_dataEtl_rename(t *testing.T) {
+	var data = map[string]interface{}{
+		"name": "map",
+		"data2": map[string]interface{}{
+			"id":      1,
+			"persons": []interface{}{map[string]interface{}{"address": "广东", "_id": 2}},
+		},
+		"address": "北京",
+	}
+	tests := []struct {
+		name       string
+		opt        []OptionFunc
+		wantResult matrixKvPairs
+		wantErr    error
+	}{
+		{
+			name: "rename",
+			opt: []OptionFunc{
+				SetRename(map[string]string{"data2.persons.address": "person_address", "**._id": "person_id", "data2.*": "data2_other"}),
+				SetRenameFunc(strings.ToUpper),
+			},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "ADDRESS", Value: "北京"}, {Key: "NAME", Value: "map"}, {Key: "data2_other", Value: 1}, {Key: "person_address", Value: "广东"}, {Key: "person_id", Value: 2}},
+			},
+		},
+		{
+			name:    "collision",
+			opt:     []OptionFunc{SetRename(map[string]string{"data2.persons.address": "address"}), SetErrorMode(ErrorModeFailFast)},
+			wantErr: ErrColumnCollision,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := newTestParser(tt.opt...)
+			gotResult, err := parser.ParseE(data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(covertHelper(gotResult), tt.wantResult) {
+				t.Errorf("ParseE() = %v, want %v", covertHelper(gotResult), tt.wantResult)
+			}
+		})
+	}
+}
+
+func Test_dataEtl_rename_collect(t *testing.T) {
+	parser := newTestParser(
+		SetRename(map[string]string{"a.x": "x", "b.x": "x"}),
+		SetErrorMode(ErrorModeCollect),
+	)
+	_, err := parser.ParseE(map[string]interface{}{"a": map[string]interface{}{"x": 1}, "b": map[string]interface{}{"x": 2}})
+	errs, ok := err.(ParseErrors)
+	if !ok || len(errs) != 1 || !errors.Is(errs[0], ErrColumnCollision) {
+		t.Fatalf("ParseE() error = %v, want one %v", err, ErrColumnCollision)
+	}
+	if !strings.Contains(errs[0].Error(), "a.x and b.x are both written to x") && !strings.Contains(errs[0].Error(), "b.x and a.x are both written to x") {
+		t.Errorf("ParseE() error = %v", errs[0])
+	}
+}
+
+func Test_dataEtl_ParseTables_rename(t *testing.T) {
+	parser := NewRelationalParser(
+		SetLogger(NewStdLogger(LevelError, os.Stdout)),
+		SetRename(map[string]string{"data.user_name": "name"}),
+	)
+	tables, err := parser.ParseTables(map[string]interface{}{
+		"name": "map",
+		"data": []interface{}{map[string]interface{}{"user_name": "小明"}},
+	})
+	if err != nil {
+		t.Fatalf("ParseTables() error = %v", err)
+	}
+	want := map[string][]map[string]interface{}{
+		RootTable: {{"_id": int64(1), "name": "map"}},
+		"data":    {{"_id": int64(2), "_parent_id": int64(1), "name": "小明"}},
+	}
+	if !reflect.DeepEqual(tables, want) {
+		t.Errorf("ParseTables() = %v, want %v", tables, want)
+	}
+}
@@ -0,0 +1,244 @@
+package alt
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// ColumnType 推断的字段类型
+type ColumnType string
+
+const (
+	// TypeNull 只出现过 nil
+	TypeNull   ColumnType = "null"
+	TypeBool   ColumnType = "bool"
+	TypeInt    ColumnType = "int"
+	TypeFloat  ColumnType = "float"
+	TypeString ColumnType = "string"
+	TypeTime   ColumnType = "time"
+)
+
+// widen 返回可以同时表示两种类型的类型, int 扩展为 float, 其他不同的类型扩展为 string
+func (t ColumnType) widen(other ColumnType) ColumnType {
+	switch {
+	case t == other || other == TypeNull:
+		return t
+	case t == TypeNull:
+		return other
+	case (t == TypeInt && other == TypeFloat) || (t == TypeFloat && other == TypeInt):
+		return TypeFloat
+	}
+	return TypeString
+}
+
+// typeOf 返回值对应的字段类型
+// json.Number 按照是否为整数推断为 int 或者 float, 浮点数总是推断为 float, 其他类型推断为 string
+// 超出 int64 范围的整数推断为 string, 避免转为 float 丢失精度
+func typeOf(v interface{}) ColumnType {
+	switch x := v.(type) {
+	case nil:
+		return TypeNull
+	case json.Number:
+		if _, err := x.Int64(); err == nil {
+			return TypeInt
+		}
+		if strings.ContainsAny(string(x), ".eE") {
+			return TypeFloat
+		}
+		return TypeString
+	case time.Time:
+		return TypeTime
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Bool:
+		return TypeBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return TypeInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rv.Uint() > math.MaxInt64 {
+			return TypeString
+		}
+		return TypeInt
+	case reflect.Float32, reflect.Float64:
+		return TypeFloat
+	}
+	return TypeString
+}
+
+// Schema 推断出的表结构, 可以序列化为 JSON 保存, 用于生成 DDL 以及与新的结构比较
+type Schema struct {
+	// Rows 推断时使用的行数
+	Rows int64 `json:"rows"`
+	// Columns 按字段名排序的字段
+	Columns []ColumnSchema `json:"columns"`
+}
+
+// ColumnSchema 推断出的字段结构
+type ColumnSchema struct {
+	Name string     `json:"name"`
+	Type ColumnType `json:"type"`
+	// Nullable 字段在某些行中不存在或者为 nil
+	Nullable bool `json:"nullable"`
+	// Count 字段存在并且不为 nil 的行数
+	Count int64 `json:"count"`
+	// Presence 字段存在并且不为 nil 的行数占所有行数的比例
+	Presence float64 `json:"presence"`
+	// Cardinality 不同取值的个数, 较少时是准确的个数, 较多时是估计值
+	Cardinality int64 `json:"cardinality"`
+	// MinLen, MaxLen 值转为字符串之后的最小和最大长度(字符数)
+	MinLen int `json:"min_len"`
+	MaxLen int `json:"max_len"`
+}
+
+// Column 按字段名查找字段
+func (s *Schema) Column(name string) (ColumnSchema, bool) {
+	for _, col := range s.Columns {
+		if col.Name == name {
+			return col, true
+		}
+	}
+	return ColumnSchema{}, false
+}
+
+// SchemaInferer 从 Parse 输出的行或者文档中推断表结构, 可以并发使用
+type SchemaInferer struct {
+	mu      sync.Mutex
+	parser  Parser
+	rows    int64
+	columns map[string]*columnStats
+}
+
+type columnStats struct {
+	typ            ColumnType
+	count          int64
+	minLen, maxLen int
+	distinct       kmv
+}
+
+// NewSchemaInferer parser 用于解析 AddDocument 的文档, 只使用 AddRows 时可以为 nil
+func NewSchemaInferer(parser Parser) *SchemaInferer {
+	return &SchemaInferer{parser: parser, columns: make(map[string]*columnStats)}
+}
+
+// AddRows 统计 Parse 输出的行
+func (s *SchemaInferer) AddRows(rows ...map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, row := range rows {
+		s.add(row)
+	}
+}
+
+// AddDocument 使用 parser 解析文档并统计输出的行
+func (s *SchemaInferer) AddDocument(data interface{}) error {
+	if s.parser == nil {
+		return fmt.Errorf("schema: parser is nil")
+	}
+	return s.parser.ParseEach(data, func(row map[string]interface{}) error {
+		s.AddRows(row)
+		return nil
+	})
+}
+
+func (s *SchemaInferer) add(row map[string]interface{}) {
+	s.rows++
+	for name, v := range row {
+		col, ok := s.columns[name]
+		if !ok {
+			col = &columnStats{typ: TypeNull, minLen: math.MaxInt32}
+			s.columns[name] = col
+		}
+		if v == nil {
+			continue
+		}
+		col.typ = col.typ.widen(typeOf(v))
+		col.count++
+		str := fmt.Sprintf("%v", v)
+		n := utf8.RuneCountInString(str)
+		if n < col.minLen {
+			col.minLen = n
+		}
+		if n > col.maxLen {
+			col.maxLen = n
+		}
+		col.distinct.add(fmt.Sprintf("%T:%s", v, str))
+	}
+}
+
+// Schema 返回当前推断出的表结构
+func (s *SchemaInferer) Schema() *Schema {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var schema = &Schema{Rows: s.rows, Columns: make([]ColumnSchema, 0, len(s.columns))}
+	for name, col := range s.columns {
+		cs := ColumnSchema{
+			Name:        name,
+			Type:        col.typ,
+			Nullable:    col.count < s.rows,
+			Count:       col.count,
+			Cardinality: col.distinct.estimate(),
+			MaxLen:      col.maxLen,
+		}
+		if col.count > 0 {
+			cs.MinLen = col.minLen
+		}
+		if s.rows > 0 {
+			cs.Presence = float64(col.count) / float64(s.rows)
+		}
+		schema.Columns = append(schema.Columns, cs)
+	}
+	sort.Slice(schema.Columns, func(i, j int) bool { return schema.Columns[i].Name < schema.Columns[j].Name })
+	return schema
+}
+
+// kmvSize kmv 保留的最小哈希值的个数, 不同取值少于 kmvSize 时是准确的个数
+const kmvSize = 256
+
+// kmv 使用 k minimum values 估计不同取值的个数
+type kmv struct {
+	// hashes 升序排列的最小的 kmvSize 个不同的哈希值
+	hashes []uint64
+}
+
+func (k *kmv) add(s string) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	x := mix64(h.Sum64())
+	if len(k.hashes) == kmvSize && x >= k.hashes[kmvSize-1] {
+		return
+	}
+	i := sort.Search(len(k.hashes), func(i int) bool { return k.hashes[i] >= x })
+	if i < len(k.hashes) && k.hashes[i] == x {
+		return
+	}
+	if len(k.hashes) < kmvSize {
+		k.hashes = append(k.hashes, 0)
+	}
+	copy(k.hashes[i+1:], k.hashes[i:])
+	k.hashes[i] = x
+}
+
+func (k *kmv) estimate() int64 {
+	if len(k.hashes) < kmvSize {
+		return int64(len(k.hashes))
+	}
+	return int64(float64(kmvSize-1) / (float64(k.hashes[kmvSize-1]) / math.MaxUint64))
+}
+
+// mix64 打散 fnv 哈希值的分布
+func mix64(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
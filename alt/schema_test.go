@@ -0,0 +1,135 @@
+package alt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSchemaInferer(t *testing.T) {
+	inferer := NewSchemaInferer(newTestParser())
+	err := inferer.AddDocument(map[string]interface{}{
+		"name": "map",
+		"data": []interface{}{
+			map[string]interface{}{"user_name": "小明", "age": 18, "score": json.Number("1")},
+			map[string]interface{}{"user_name": "小海", "age": 17.5, "score": json.Number("2.5")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddDocument() error = %v", err)
+	}
+	inferer.AddRows(map[string]interface{}{"name": true, "at": time.Unix(0, 0), "extra": nil})
+	inferer.AddRows(map[string]interface{}{"name": "map", "at": time.Unix(1, 0), "data.age": int64(20)})
+
+	want := &Schema{Rows: 4, Columns: []ColumnSchema{
+		{Name: "at", Type: TypeTime, Nullable: true, Count: 2, Presence: 0.5, Cardinality: 2, MinLen: len(time.Unix(0, 0).String()), MaxLen: len(time.Unix(0, 0).String())},
+		{Name: "data.age", Type: TypeFloat, Nullable: true, Count: 3, Presence: 0.75, Cardinality: 3, MinLen: 2, MaxLen: 4},
+		{Name: "data.score", Type: TypeFloat, Nullable: true, Count: 2, Presence: 0.5, Cardinality: 2, MinLen: 1, MaxLen: 3},
+		{Name: "data.user_name", Type: TypeString, Nullable: true, Count: 2, Presence: 0.5, Cardinality: 2, MinLen: 2, MaxLen: 2},
+		{Name: "extra", Type: TypeNull, Nullable: true},
+		{Name: "name", Type: TypeString, Count: 4, Presence: 1, Cardinality: 2, MinLen: 3, MaxLen: 4},
+	}}
+	got := inferer.Schema()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Schema() = %+v, want %+v", got, want)
+	}
+
+	// 序列化之后可以还原
+	b, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var decoded Schema
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, want) {
+		t.Errorf("Unmarshal() = %+v, want %+v", decoded, want)
+	}
+	if col, ok := decoded.Column("data.score"); !ok || col.Type != TypeFloat {
+		t.Errorf("Column() = %+v, %v", col, ok)
+	}
+	if _, ok := decoded.Column("missing"); ok {
+		t.Errorf("Column() found missing column")
+	}
+}
+
+func Test_typeOf(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want ColumnType
+	}{
+		{nil, TypeNull},
+		{true, TypeBool},
+		{json.Number("12"), TypeInt},
+		{json.Number("-9223372036854775808"), TypeInt},
+		{json.Number("1.5"), TypeFloat},
+		{json.Number("1e20"), TypeFloat},
+		// 超出 int64 范围的整数转为 float 会丢失精度
+		{json.Number("18446744073709551615"), TypeString},
+		{json.Number("123456789012345678901234567890"), TypeString},
+		{uint64(math.MaxInt64), TypeInt},
+		{uint64(math.MaxUint64), TypeString},
+		{float32(1), TypeFloat},
+		{time.Time{}, TypeTime},
+		{"a", TypeString},
+	}
+	for _, tt := range tests {
+		if got := typeOf(tt.v); got != tt.want {
+			t.Errorf("typeOf(%#v) = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestSchema_Column(t *testing.T) {
+	// 手工构造或者修改过的 Schema 不一定按字段名排序
+	schema := &Schema{Columns: []ColumnSchema{{Name: "b", Type: TypeInt}, {Name: "a", Type: TypeString}, {Name: "c", Type: TypeFloat}}}
+	for _, col := range schema.Columns {
+		if got, ok := schema.Column(col.Name); !ok || got != col {
+			t.Errorf("Column(%v) = %+v, %v", col.Name, got, ok)
+		}
+	}
+	if _, ok := schema.Column("d"); ok {
+		t.Errorf("Column() found missing column")
+	}
+}
+
+func TestColumnType_widen(t *testing.T) {
+	tests := []struct {
+		a, b, want ColumnType
+	}{
+		{TypeNull, TypeInt, TypeInt},
+		{TypeInt, TypeNull, TypeInt},
+		{TypeInt, TypeFloat, TypeFloat},
+		{TypeFloat, TypeInt, TypeFloat},
+		{TypeFloat, TypeString, TypeString},
+		{TypeBool, TypeInt, TypeString},
+		{TypeTime, TypeTime, TypeTime},
+		{TypeTime, TypeInt, TypeString},
+	}
+	for _, tt := range tests {
+		if got := tt.a.widen(tt.b); got != tt.want {
+			t.Errorf("%v.widen(%v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func Test_kmv_estimate(t *testing.T) {
+	var k kmv
+	for i := 0; i < 100; i++ {
+		k.add(fmt.Sprint(i % 50))
+	}
+	if got := k.estimate(); got != 50 {
+		t.Errorf("estimate() = %v, want 50", got)
+	}
+	for i := 0; i < 100000; i++ {
+		k.add(fmt.Sprint(i))
+	}
+	// k = 256 时标准误差约为 6%
+	if got := k.estimate(); got < 80000 || got > 120000 {
+		t.Errorf("estimate() = %v, want about 100000", got)
+	}
+}
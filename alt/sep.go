@@ -3,3 +3,10 @@ package alt
 type Separator interface {
 	AppendToPrefix(prefix string, key interface{}) string
 }
+
+// PathSeparator 可以将扁平化之后的字段名拆分为每一级字段名的 Separator
+type PathSeparator interface {
+	Separator
+	// Split 将 AppendToPrefix 生成的字段名拆分为每一级字段名, 字段名格式错误时返回 ErrInvalidKey
+	Split(key string) ([]string, error)
+}
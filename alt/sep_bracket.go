@@ -0,0 +1,92 @@
+package alt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BracketSeparator 使用 JavaScript 风格的方括号表示字段名, 例如 a["b.c"][0]
+// 整数字段名(列表下标)使用 [0], 不包含 . [ ] " \ 并且不是整数的字段名使用 . 连接, 其他字段名使用带引号的 ["b.c"]
+type BracketSeparator struct{}
+
+func (BracketSeparator) AppendToPrefix(prefix string, key interface{}) string {
+	switch k := key.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%s[%d]", prefix, k)
+	}
+	s := fmt.Sprintf("%v", key)
+	if !bareKey(s) {
+		return prefix + "[" + strconv.Quote(s) + "]"
+	}
+	if prefix == "" {
+		return s
+	}
+	return prefix + "." + s
+}
+
+func (BracketSeparator) Split(key string) (segs []string, err error) {
+	for i := 0; i < len(key); {
+		switch {
+		case key[i] == '[':
+			var seg string
+			if seg, i, err = splitBracket(key, i); err != nil {
+				return nil, err
+			}
+			segs = append(segs, seg)
+			continue
+		case key[i] == '.' && i > 0:
+			i++
+		case i > 0:
+			return nil, fmt.Errorf("%w: %q has unexpected %q at offset %d", ErrInvalidKey, key, key[i], i)
+		}
+		j := i
+		for j < len(key) && key[j] != '.' && key[j] != '[' {
+			j++
+		}
+		if !bareKey(key[i:j]) {
+			return nil, fmt.Errorf("%w: %q has invalid field name at offset %d", ErrInvalidKey, key, i)
+		}
+		segs = append(segs, key[i:j])
+		i = j
+	}
+	if len(segs) == 0 {
+		return []string{""}, nil
+	}
+	return segs, nil
+}
+
+// splitBracket 解析 i 处的 [0] 或者 ["b.c"], 返回字段名和 ] 之后的位置
+func splitBracket(key string, i int) (string, int, error) {
+	j := i + 1
+	if j < len(key) && key[j] == '"' {
+		// 查找没有被转义的右引号
+		for j++; j < len(key) && key[j] != '"'; j++ {
+			if key[j] == '\\' {
+				j++
+			}
+		}
+		if j+1 < len(key) && key[j+1] == ']' {
+			if s, err := strconv.Unquote(key[i+1 : j+1]); err == nil {
+				return s, j + 2, nil
+			}
+		}
+		return "", 0, fmt.Errorf("%w: %q has invalid quoted field name at offset %d", ErrInvalidKey, key, i)
+	}
+	for j < len(key) && key[j] >= '0' && key[j] <= '9' {
+		j++
+	}
+	if j == i+1 || j == len(key) || key[j] != ']' {
+		return "", 0, fmt.Errorf("%w: %q has invalid index at offset %d", ErrInvalidKey, key, i)
+	}
+	return key[i+1 : j], j + 1, nil
+}
+
+// bareKey 判断字段名是否可以不使用方括号表示
+func bareKey(s string) bool {
+	if s == "" || strings.ContainsAny(s, `.[]"\`) {
+		return false
+	}
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err != nil
+}
@@ -0,0 +1,47 @@
+package alt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EscapeSeparator 使用 \ 转义字段名中的分隔符和 \, 例如 a\.b.c 表示 a.b 之下的字段 c
+type EscapeSeparator string
+
+func (c EscapeSeparator) AppendToPrefix(prefix string, key interface{}) string {
+	s := fmt.Sprintf("%v", key)
+	if c != "" && (strings.Contains(s, string(c)) || strings.Contains(s, `\`)) {
+		s = strings.NewReplacer(`\`, `\\`, string(c), `\`+string(c)).Replace(s)
+	}
+	if prefix == "" {
+		return s
+	}
+	return prefix + string(c) + s
+}
+
+func (c EscapeSeparator) Split(key string) ([]string, error) {
+	if c == "" {
+		return []string{key}, nil
+	}
+	var (
+		segs []string
+		seg  strings.Builder
+	)
+	for i := 0; i < len(key); i++ {
+		switch {
+		case key[i] == '\\':
+			if i+1 == len(key) {
+				return nil, fmt.Errorf("%w: %q ends with \\", ErrInvalidKey, key)
+			}
+			i++
+			seg.WriteByte(key[i])
+		case strings.HasPrefix(key[i:], string(c)):
+			segs = append(segs, seg.String())
+			seg.Reset()
+			i += len(c) - 1
+		default:
+			seg.WriteByte(key[i])
+		}
+	}
+	return append(segs, seg.String()), nil
+}
@@ -0,0 +1,42 @@
+package alt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JSONPointerSeparator 使用 JSON Pointer(RFC 6901) 表示字段名, 例如 /a/b~1c/0
+// 字段名中的 ~ 和 / 分别转义为 ~0 和 ~1
+type JSONPointerSeparator struct{}
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+func (JSONPointerSeparator) AppendToPrefix(prefix string, key interface{}) string {
+	return prefix + "/" + pointerEscaper.Replace(fmt.Sprintf("%v", key))
+}
+
+func (JSONPointerSeparator) Split(key string) ([]string, error) {
+	if key == "" {
+		return nil, nil
+	}
+	if key[0] != '/' {
+		return nil, fmt.Errorf("%w: %q does not start with /", ErrInvalidKey, key)
+	}
+	segs := strings.Split(key[1:], "/")
+	for i, seg := range segs {
+		for j := 0; j < len(seg); j++ {
+			if seg[j] != '~' {
+				continue
+			}
+			if j+1 == len(seg) || (seg[j+1] != '0' && seg[j+1] != '1') {
+				return nil, fmt.Errorf("%w: %q has invalid escape in %q", ErrInvalidKey, key, seg)
+			}
+			j++
+		}
+		segs[i] = pointerUnescaper.Replace(seg)
+	}
+	return segs, nil
+}
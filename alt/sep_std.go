@@ -1,6 +1,9 @@
 package alt
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type StrSeparator string
 
@@ -10,3 +13,11 @@ func (c StrSeparator) AppendToPrefix(prefix string, key interface{}) string {
 	}
 	return fmt.Sprintf("%s%v%v", prefix, c, key)
 }
+
+// Split 使用分隔符拆分字段名, 字段名中包含分隔符时无法区分, 需要无歧义的拆分时使用 EscapeSeparator
+func (c StrSeparator) Split(key string) ([]string, error) {
+	if c == "" {
+		return []string{key}, nil
+	}
+	return strings.Split(key, string(c)), nil
+}
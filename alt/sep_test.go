@@ -0,0 +1,103 @@
+package alt
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// appendKeys 依次追加字段名, 模拟解析时生成的字段名
+func appendKeys(sep Separator, keys ...interface{}) (prefix string) {
+	for _, key := range keys {
+		prefix = sep.AppendToPrefix(prefix, key)
+	}
+	return prefix
+}
+
+func TestPathSeparator_Split(t *testing.T) {
+	tests := []struct {
+		name string
+		sep  PathSeparator
+		keys []interface{}
+		want string
+		segs []string
+	}{
+		{name: "str", sep: StrSeparator("_"), keys: []interface{}{"a", "b", 0}, want: "a_b_0", segs: []string{"a", "b", "0"}},
+		{name: "escape", sep: EscapeSeparator("."), keys: []interface{}{"a", "b.c", 0}, want: `a.b\.c.0`, segs: []string{"a", "b.c", "0"}},
+		{name: "escape_backslash", sep: EscapeSeparator("."), keys: []interface{}{`a\`, "b"}, want: `a\\.b`, segs: []string{`a\`, "b"}},
+		{name: "escape_multi", sep: EscapeSeparator("__"), keys: []interface{}{"a__b", "c_d"}, want: `a\__b__c_d`, segs: []string{"a__b", "c_d"}},
+		{name: "bracket", sep: BracketSeparator{}, keys: []interface{}{"a", "b.c", 0, "d"}, want: `a["b.c"][0].d`, segs: []string{"a", "b.c", "0", "d"}},
+		{name: "bracket_quote", sep: BracketSeparator{}, keys: []interface{}{`x"]`, "0", ""}, want: `["x\"]"]["0"][""]`, segs: []string{`x"]`, "0", ""}},
+		{name: "bracket_root_index", sep: BracketSeparator{}, keys: []interface{}{1, "名字"}, want: `[1].名字`, segs: []string{"1", "名字"}},
+		{name: "pointer", sep: JSONPointerSeparator{}, keys: []interface{}{"a", "b/c", "~d", 0}, want: "/a/b~1c/~0d/0", segs: []string{"a", "b/c", "~d", "0"}},
+		{name: "pointer_empty", sep: JSONPointerSeparator{}, keys: []interface{}{"", "a"}, want: "//a", segs: []string{"", "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := appendKeys(tt.sep, tt.keys...)
+			if got != tt.want {
+				t.Fatalf("AppendToPrefix() = %v, want %v", got, tt.want)
+			}
+			segs, err := tt.sep.Split(got)
+			if err != nil {
+				t.Fatalf("Split() error = %v", err)
+			}
+			if !reflect.DeepEqual(segs, tt.segs) {
+				t.Errorf("Split() = %q, want %q", segs, tt.segs)
+			}
+		})
+	}
+}
+
+func TestPathSeparator_Split_invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		sep  PathSeparator
+		key  string
+	}{
+		{name: "escape_trailing", sep: EscapeSeparator("."), key: `a.b\`},
+		{name: "bracket_unclosed", sep: BracketSeparator{}, key: `a["b`},
+		{name: "bracket_index", sep: BracketSeparator{}, key: `a[x]`},
+		{name: "bracket_empty", sep: BracketSeparator{}, key: `a..b`},
+		{name: "bracket_trailing", sep: BracketSeparator{}, key: `a[0]b`},
+		{name: "bracket_number", sep: BracketSeparator{}, key: `a.0`},
+		{name: "pointer_relative", sep: JSONPointerSeparator{}, key: "a/b"},
+		{name: "pointer_escape", sep: JSONPointerSeparator{}, key: "/a~2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if segs, err := tt.sep.Split(tt.key); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("Split() = %q, error = %v, want %v", segs, err, ErrInvalidKey)
+			}
+		})
+	}
+}
+
+func Test_dataEtl_separator(t *testing.T) {
+	var data = map[string]interface{}{
+		"a.b":  1,
+		"a":    map[string]interface{}{"b": 2},
+		"tags": []interface{}{"x"},
+	}
+	tests := []struct {
+		name string
+		sep  PathSeparator
+		want []pair
+	}{
+		{name: "escape", sep: EscapeSeparator("."), want: []pair{{Key: "a.b", Value: 2}, {Key: `a\.b`, Value: 1}, {Key: "tags.0", Value: "x"}}},
+		{name: "bracket", sep: BracketSeparator{}, want: []pair{{Key: `["a.b"]`, Value: 1}, {Key: "a.b", Value: 2}, {Key: "tags[0]", Value: "x"}}},
+		{name: "pointer", sep: JSONPointerSeparator{}, want: []pair{{Key: "/a.b", Value: 1}, {Key: "/a/b", Value: 2}, {Key: "/tags/0", Value: "x"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := newTestParser(SetSeparator(tt.sep), SetExplode(ExplodeIndex, "tags"), SetErrorMode(ErrorModeFailFast))
+			gotResult, err := parser.ParseE(data)
+			if err != nil {
+				t.Fatalf("ParseE() error = %v", err)
+			}
+			if want := (matrixKvPairs{tt.want}); !reflect.DeepEqual(covertHelper(gotResult), want) {
+				t.Errorf("ParseE() = %v, want %v", covertHelper(gotResult), want)
+			}
+		})
+	}
+}
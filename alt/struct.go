@@ -0,0 +1,128 @@
+package alt
+
+import (
+	"reflect"
+)
+
+// indirect 解引用指针和接口, nil 指针返回 nil
+func indirect(data interface{}) interface{} {
+	if data == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(data)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	return rv.Interface()
+}
+
+// structField struct 中可见的字段
+type structField struct {
+	tag   structTag
+	value interface{}
+	// 匿名嵌入的层级
+	depth int
+	// 匿名嵌入的指针已经在当前路径上, at 为引用第一次出现的字段
+	cycle bool
+	at    string
+}
+
+// visibleFields 返回 struct 中可见的字段, 匿名嵌入的 struct 的字段提升到当前层级, 与 encoding/json 一致
+// 同名的字段嵌入层级浅的优先, 层级相同时设置了 tag 名字的优先, 否则都忽略
+func (c *dataEtl) visibleFields(rv reflect.Value, prefix string) []structField {
+	var all []structField
+	c.embeddedFields(rv, prefix, 0, &all)
+
+	var names []string
+	var byName = make(map[string][]structField)
+	for _, sf := range all {
+		if _, ok := byName[sf.tag.name]; !ok {
+			names = append(names, sf.tag.name)
+		}
+		byName[sf.tag.name] = append(byName[sf.tag.name], sf)
+	}
+	var fields = make([]structField, 0, len(names))
+	for _, name := range names {
+		if sf, ok := dominantField(byName[name]); ok {
+			fields = append(fields, sf)
+		}
+	}
+	return fields
+}
+
+// embeddedFields 收集 struct 及其匿名嵌入的 struct 中的字段
+// 匿名嵌入的指针形成循环时不再展开, 以嵌入的类型名作为字段名, 由 CyclePolicy 处理
+func (c *dataEtl) embeddedFields(rv reflect.Value, prefix string, depth int, all *[]structField) {
+	rt := rv.Type()
+	for i := 0; i < rv.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := c.parseTag(sf)
+		if tag.skip {
+			continue
+		}
+		fv := rv.Field(i)
+		if sf.Anonymous && !tag.named {
+			t := sf.Type
+			if t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.Struct {
+				if fv.Kind() != reflect.Ptr {
+					c.embeddedFields(fv, prefix, depth+1, all)
+					continue
+				}
+				if fv.IsNil() {
+					continue
+				}
+				ref, at, cycle := c.visitRef(reference{ptr: fv.Pointer(), typ: fv.Type()}, prefix)
+				if cycle {
+					// 未导出的字段无法读取, 使用同类型的 nil 指针
+					v := reflect.Zero(fv.Type()).Interface()
+					*all = append(*all, structField{tag: tag, value: v, depth: depth, cycle: true, at: at})
+					continue
+				}
+				c.embeddedFields(fv.Elem(), prefix, depth+1, all)
+				c.leave(ref)
+				continue
+			}
+		}
+		// 未导出的字段无法读取
+		if sf.PkgPath != "" {
+			continue
+		}
+		if tag.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		*all = append(*all, structField{tag: tag, value: fv.Interface(), depth: depth})
+	}
+}
+
+// dominantField 返回同名字段中优先的字段
+func dominantField(fields []structField) (structField, bool) {
+	var min = fields[0].depth
+	for _, sf := range fields {
+		if sf.depth < min {
+			min = sf.depth
+		}
+	}
+	var dominant, tagged []structField
+	for _, sf := range fields {
+		if sf.depth != min {
+			continue
+		}
+		dominant = append(dominant, sf)
+		if sf.tag.named {
+			tagged = append(tagged, sf)
+		}
+	}
+	if len(dominant) == 1 {
+		return dominant[0], true
+	}
+	if len(tagged) == 1 {
+		return tagged[0], true
+	}
+	return structField{}, false
+}
@@ -0,0 +1,142 @@
+package alt
+
+import (
+	"reflect"
+	"testing"
+)
+
+type Base struct {
+	Id   int
+	Name string
+}
+
+type meta struct {
+	Source string
+}
+
+type Address struct {
+	City string
+}
+
+type Event struct {
+	Base
+	*meta
+	Name    string
+	Address *Address
+	Home    *Address
+	Extra   interface{}
+	Tags    []*string
+}
+
+func Test_indirect(t *testing.T) {
+	var n = 1
+	var p = &n
+	var nilPtr *int
+	var iface interface{} = &p
+	tests := []struct {
+		name string
+		data interface{}
+		want interface{}
+	}{
+		{name: "nil", data: nil, want: nil},
+		{name: "value", data: 1, want: 1},
+		{name: "ptr", data: p, want: 1},
+		{name: "ptr_ptr", data: &p, want: 1},
+		{name: "interface", data: &iface, want: 1},
+		{name: "nil_ptr", data: nilPtr, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := indirect(tt.data); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("indirect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_dataEtl_pointer(t *testing.T) {
+	var a, b = "a", "b"
+	var data = &Event{
+		Base:    Base{Id: 1, Name: "base"},
+		meta:    &meta{Source: "api"},
+		Name:    "event",
+		Address: &Address{City: "广州"},
+		Extra:   &Address{City: "深圳"},
+		Tags:    []*string{&a, &b},
+	}
+	tests := []struct {
+		name       string
+		data       interface{}
+		wantResult matrixKvPairs
+	}{
+		{
+			name: "pointer",
+			data: data,
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "Address.City", Value: "广州"}, {Key: "Extra.City", Value: "深圳"}, {Key: "Id", Value: 1}, {Key: "Name", Value: "event"}, {Key: "Source", Value: "api"}, {Key: "Tags", Value: "a"}},
+				[]pair{{Key: "Address.City", Value: "广州"}, {Key: "Extra.City", Value: "深圳"}, {Key: "Id", Value: 1}, {Key: "Name", Value: "event"}, {Key: "Source", Value: "api"}, {Key: "Tags", Value: "b"}},
+			},
+		},
+		{
+			name: "map_pointer",
+			data: map[string]interface{}{"event": &Base{Id: 2}, "nil": (*Base)(nil)},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "event.Id", Value: 2}, {Key: "event.Name", Value: ""}},
+			},
+		},
+		{
+			name: "nil_pointer",
+			data: (*Event)(nil),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := newTestParser()
+			gotResult, err := parser.ParseE(tt.data)
+			if err != nil {
+				t.Fatalf("ParseE() error = %v", err)
+			}
+			if !reflect.DeepEqual(covertHelper(gotResult), tt.wantResult) {
+				t.Errorf("ParseE() = %v, want %v", covertHelper(gotResult), tt.wantResult)
+			}
+		})
+	}
+}
+
+func Test_dataEtl_embedded(t *testing.T) {
+	type inner struct {
+		Name string `json:"name"`
+	}
+	type conflict struct {
+		Id int
+	}
+	type other struct {
+		Id int
+	}
+	type outer struct {
+		inner
+		conflict
+		other
+		Base  `json:"base"`
+		Value int
+	}
+	var data = outer{
+		inner:    inner{Name: "inner"},
+		conflict: conflict{Id: 1},
+		other:    other{Id: 2},
+		Base:     Base{Id: 3, Name: "base"},
+		Value:    4,
+	}
+	parser := newTestParser()
+	gotResult, err := parser.ParseE(data)
+	if err != nil {
+		t.Fatalf("ParseE() error = %v", err)
+	}
+	// 同一层级的 Id 冲突时都被忽略, 设置了 tag 名字的嵌入字段作为普通字段
+	want := matrixKvPairs{
+		[]pair{{Key: "Value", Value: 4}, {Key: "base.Id", Value: 3}, {Key: "base.Name", Value: "base"}, {Key: "name", Value: "inner"}},
+	}
+	if !reflect.DeepEqual(covertHelper(gotResult), want) {
+		t.Errorf("ParseE() = %v, want %v", covertHelper(gotResult), want)
+	}
+}
@@ -0,0 +1,100 @@
+package alt
+
+import "sort"
+
+// Table 按固定的字段顺序输出的结果, 每一行的长度与 Columns 相同, 不存在的字段使用 nil 填充
+type Table struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// TableParser 将文档解析为 Table
+type TableParser interface {
+	ParseTable(data interface{}) (table *Table, err error)
+}
+
+func NewTableParser(opt ...OptionFunc) TableParser {
+	return NewDataEtlParser(opt...).(*dataEtl)
+}
+
+// SetColumns 设置 ParseTable 输出的字段及其顺序, 不在其中的字段会被丢弃
+// 不设置时输出所有行中出现过的字段, 按字段名排序
+func SetColumns(columns ...string) OptionFunc {
+	return func(c *dataEtl) {
+		c.tableColumns = columns
+	}
+}
+
+// NewTable 将 Parse 输出的行转为 Table, columns 为空时使用所有行中出现过的字段, 按字段名排序
+func NewTable(columns []string, rows []map[string]interface{}) *Table {
+	if len(columns) == 0 {
+		var seen = make(map[string]struct{})
+		for _, row := range rows {
+			for key := range row {
+				if _, ok := seen[key]; !ok {
+					seen[key] = struct{}{}
+					columns = append(columns, key)
+				}
+			}
+		}
+		sort.Strings(columns)
+	}
+	var t = &Table{Columns: columns, Rows: make([][]interface{}, 0, len(rows))}
+	for _, row := range rows {
+		t.append(row)
+	}
+	return t
+}
+
+// append 按字段顺序添加一行
+func (t *Table) append(row map[string]interface{}) {
+	var values = make([]interface{}, len(t.Columns))
+	for i, name := range t.Columns {
+		values[i] = row[name]
+	}
+	t.Rows = append(t.Rows, values)
+}
+
+// Index 返回字段的下标, 字段不存在时返回 -1
+func (t *Table) Index(name string) int {
+	for i, column := range t.Columns {
+		if column == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Column 返回字段在每一行中的值, 字段不存在时返回 nil
+func (t *Table) Column(name string) []interface{} {
+	i := t.Index(name)
+	if i < 0 {
+		return nil
+	}
+	var values = make([]interface{}, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		values = append(values, row[i])
+	}
+	return values
+}
+
+// ParseTable 解析数据并输出为 Table, 根据 ErrorMode 返回解析过程中的错误
+func (c *dataEtl) ParseTable(data interface{}) (table *Table, err error) {
+	if len(c.tableColumns) == 0 {
+		rows, err := c.ParseE(data)
+		if rows == nil {
+			return nil, err
+		}
+		return NewTable(nil, rows), err
+	}
+	// 字段固定时不需要保存所有的行
+	table = &Table{Columns: c.tableColumns, Rows: make([][]interface{}, 0)}
+	err = c.ParseEach(data, func(row map[string]interface{}) error {
+		table.append(row)
+		return nil
+	})
+	if _, ok := err.(ParseErrors); err != nil && !ok {
+		return nil, err
+	}
+	return table, err
+}
@@ -0,0 +1,79 @@
+package alt
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_dataEtl_ParseTable(t *testing.T) {
+	var data = map[string]interface{}{
+		"name": "map",
+		"data": []interface{}{
+			map[string]interface{}{"user_name": "小明", "age": 18},
+			map[string]interface{}{"user_name": "小海"},
+		},
+	}
+	tests := []struct {
+		name    string
+		opt     []OptionFunc
+		data    interface{}
+		want    *Table
+		wantErr error
+	}{
+		{
+			name: "union",
+			data: data,
+			want: &Table{
+				Columns: []string{"data.age", "data.user_name", "name"},
+				Rows:    [][]interface{}{{18, "小明", "map"}, {nil, "小海", "map"}},
+			},
+		},
+		{
+			name: "columns",
+			opt:  []OptionFunc{SetColumns("name", "data.user_name", "missing")},
+			data: data,
+			want: &Table{
+				Columns: []string{"name", "data.user_name", "missing"},
+				Rows:    [][]interface{}{{"map", "小明", nil}, {"map", "小海", nil}},
+			},
+		},
+		{
+			name: "empty",
+			data: nil,
+			want: &Table{Columns: nil, Rows: [][]interface{}{}},
+		},
+		{
+			name:    "error",
+			opt:     []OptionFunc{SetMaxRows(1, LimitError)},
+			data:    data,
+			wantErr: ErrMaxRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := append([]OptionFunc{SetLogger(NewStdLogger(LevelError, os.Stdout))}, tt.opt...)
+			got, err := NewTableParser(opt...).ParseTable(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseTable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTable_Column(t *testing.T) {
+	table := NewTable(nil, []map[string]interface{}{{"a": 1}, {"b": 2}})
+	if got := table.Column("a"); !reflect.DeepEqual(got, []interface{}{1, nil}) {
+		t.Errorf("Column() = %v", got)
+	}
+	if got := table.Index("b"); got != 1 {
+		t.Errorf("Index() = %v, want 1", got)
+	}
+	if got := table.Column("c"); got != nil {
+		t.Errorf("Column() = %v, want nil", got)
+	}
+}
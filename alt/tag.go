@@ -0,0 +1,91 @@
+package alt
+
+import (
+	"reflect"
+	"strings"
+)
+
+// OmegaTag 专用的 struct tag, 优先于 SetTagKey 设置的 tag
+// 格式为 omega:"name,opt1,opt2", name 为空时使用 SetTagKey 设置的 tag 或者字段名, omega:"-" 忽略字段
+// 支持的选项:
+//
+//	omitempty 字段为零值时忽略
+//	json      不再展开字段, 序列化为 JSON 字符串
+//	rawjson   不再展开字段, 序列化为 json.RawMessage
+const OmegaTag = "omega"
+
+// DefaultTagKey 默认读取的 struct tag
+const DefaultTagKey = "json"
+
+// SetTagKey 设置读取字段名的 struct tag, 例如 json, yaml, 为空时使用字段名
+// tag 支持 "-" 和 omitempty 选项, 与 encoding/json 一致
+func SetTagKey(key string) OptionFunc {
+	return func(c *dataEtl) {
+		c.tagKey = key
+	}
+}
+
+// structTag 解析之后的 struct tag
+type structTag struct {
+	name      string
+	named     bool
+	skip      bool
+	omitEmpty bool
+	json      bool
+	format    JSONFormat
+}
+
+// parseTag 解析 struct 字段的 tag, omega tag 的设置优先
+func (c *dataEtl) parseTag(sf reflect.StructField) (t structTag) {
+	t.name = sf.Name
+	for _, key := range []string{c.tagKey, OmegaTag} {
+		if key == "" {
+			continue
+		}
+		tag, ok := sf.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		// omega tag 可以重新包含 SetTagKey 设置的 tag 忽略的字段
+		t.skip = tag == "-"
+		if t.skip {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		if opts[0] != "" {
+			t.name, t.named = opts[0], true
+		}
+		for _, opt := range opts[1:] {
+			switch opt {
+			case "omitempty":
+				t.omitEmpty = true
+			case "json":
+				t.json = t.json || key == OmegaTag
+			case "rawjson":
+				if key == OmegaTag {
+					t.json, t.format = true, JSONRaw
+				}
+			}
+		}
+	}
+	return t
+}
+
+// isEmptyValue 判断是否为零值, 与 encoding/json 的 omitempty 一致
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package alt
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_dataEtl_structTag(t *testing.T) {
+	type address struct {
+		City string `json:"city"`
+		Zip  string `json:"zip,omitempty"`
+	}
+	type event struct {
+		Id       int               `json:"id" yaml:"event_id"`
+		Name     string            `json:"name,omitempty"`
+		Secret   string            `json:"-"`
+		Internal string            `omega:"-"`
+		Renamed  string            `json:"json_name" omega:"omega_name"`
+		Token    string            `json:"-" omega:"token"`
+		Attrs    map[string]string `omega:"attrs,json"`
+		Raw      []int             `omega:",rawjson"`
+		Address  address           `json:"address"`
+		Plain    int
+		hidden   int
+	}
+	var data = event{
+		Id:       1,
+		Secret:   "s",
+		Internal: "i",
+		Renamed:  "r",
+		Token:    "t",
+		Attrs:    map[string]string{"k": "v"},
+		Raw:      []int{1, 2},
+		Address:  address{City: "广州"},
+		Plain:    2,
+		hidden:   3,
+	}
+	tests := []struct {
+		name       string
+		opt        []OptionFunc
+		wantResult matrixKvPairs
+	}{
+		{
+			name: "json_tag",
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "Plain", Value: 2}, {Key: "Raw", Value: json.RawMessage(`[1,2]`)}, {Key: "address.city", Value: "广州"}, {Key: "attrs", Value: `{"k":"v"}`}, {Key: "id", Value: 1}, {Key: "omega_name", Value: "r"}, {Key: "token", Value: "t"}},
+			},
+		},
+		{
+			name: "yaml_tag",
+			opt:  []OptionFunc{SetTagKey("yaml")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "Address.City", Value: "广州"}, {Key: "Address.Zip", Value: ""}, {Key: "Name", Value: ""}, {Key: "Plain", Value: 2}, {Key: "Raw", Value: json.RawMessage(`[1,2]`)}, {Key: "Secret", Value: "s"}, {Key: "attrs", Value: `{"k":"v"}`}, {Key: "event_id", Value: 1}, {Key: "omega_name", Value: "r"}, {Key: "token", Value: "t"}},
+			},
+		},
+		{
+			name: "tag_path",
+			opt:  []OptionFunc{SetIgnorePaths("address.city", "Raw", "attrs")},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "Plain", Value: 2}, {Key: "id", Value: 1}, {Key: "omega_name", Value: "r"}, {Key: "token", Value: "t"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkParse(t, newTestParser(tt.opt...), data, tt.wantResult)
+		})
+	}
+}
+
+func Test_dataEtl_ParseTables_structTag(t *testing.T) {
+	type item struct {
+		Sku   string            `json:"sku"`
+		Attrs map[string]string `omega:",json"`
+	}
+	type order struct {
+		Id    int    `json:"id"`
+		Items []item `json:"items"`
+	}
+	parser := NewRelationalParser(SetLogger(NewStdLogger(LevelError, os.Stdout)))
+	tables, err := parser.ParseTables(order{Id: 1, Items: []item{{Sku: "a", Attrs: map[string]string{"k": "v"}}}})
+	if err != nil {
+		t.Fatalf("ParseTables() error = %v", err)
+	}
+	want := map[string][]map[string]interface{}{
+		RootTable: {{"_id": int64(1), "id": 1}},
+		"items":   {{"_id": int64(2), "_parent_id": int64(1), "sku": "a", "Attrs": `{"k":"v"}`}},
+	}
+	if !reflect.DeepEqual(tables, want) {
+		t.Errorf("ParseTables() = %v, want %v", tables, want)
+	}
+}
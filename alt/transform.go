@@ -0,0 +1,48 @@
+package alt
+
+import (
+	"errors"
+)
+
+// TransformFunc 转换字段的值, path 为扁平化之后的字段名
+// 返回 ErrDrop 时丢弃该字段, 返回其他错误时中断解析并返回该错误
+type TransformFunc func(path string, v interface{}) (interface{}, error)
+
+type transformRule struct {
+	spec pathSpec
+	fn   TransformFunc
+}
+
+// SetTransform 设置基础类型和叶子类型字段的转换函数, 例如去除空格, 解析时间, 脱敏
+// pattern 为字段路径(格式见 pathSpec), 支持通配符, 多个匹配的转换函数按设置的顺序依次调用
+func SetTransform(pattern string, fn TransformFunc) OptionFunc {
+	return func(c *dataEtl) {
+		c.transforms = append(c.transforms, transformRule{spec: parsePathSpec(pattern), fn: fn})
+	}
+}
+
+// transform 依次调用匹配的转换函数, drop 为 true 时丢弃该字段
+func (c *dataEtl) transform(v interface{}, prefix string, path []string, depth int) (out interface{}, drop bool, err error) {
+	out = v
+	for _, rule := range c.transforms {
+		if !rule.spec.match(path) {
+			continue
+		}
+		if out, err = rule.fn(prefix, out); errors.Is(err, ErrDrop) {
+			c.logger.Debug("transform: value is dropped ", prefix)
+			return nil, true, nil
+		} else if err != nil {
+			return nil, false, &ParseError{Path: prefix, Kind: kindOf(v), Depth: depth, Err: err}
+		}
+	}
+	return out, false, nil
+}
+
+// setValue 将转换之后的值填充到 row
+func (c *dataEtl) setValue(row map[string]interface{}, key string, v interface{}, prefix string, path []string, depth int) error {
+	v, drop, err := c.transform(v, prefix, path, depth)
+	if err != nil || drop {
+		return err
+	}
+	return c.put(row, key, path, v, depth)
+}
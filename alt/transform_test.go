@@ -0,0 +1,100 @@
+package alt
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_dataEtl_transform(t *testing.T) {
+	var errInvalid = errors.New("invalid")
+	trim := func(path string, v interface{}) (interface{}, error) {
+		if s, ok := v.(string); ok {
+			return strings.TrimSpace(s), nil
+		}
+		return v, nil
+	}
+	mask := func(path string, v interface{}) (interface{}, error) {
+		return "***", nil
+	}
+	cents := func(path string, v interface{}) (interface{}, error) {
+		return float64(v.(int)) / 100, nil
+	}
+	drop := func(path string, v interface{}) (interface{}, error) {
+		return nil, ErrDrop
+	}
+	parseTime := func(path string, v interface{}) (interface{}, error) {
+		tm, err := time.Parse(time.RFC3339, v.(string))
+		if err != nil {
+			return nil, errInvalid
+		}
+		return tm, nil
+	}
+	var data = map[string]interface{}{
+		"name":  " cpu ",
+		"price": 1250,
+		"users": []interface{}{
+			map[string]interface{}{"name": "小明 ", "phone": "13800000000"},
+			map[string]interface{}{"name": " 小海", "phone": "13900000000"},
+		},
+		"created": "2021-01-02T03:04:05Z",
+	}
+	tests := []struct {
+		name       string
+		opt        []OptionFunc
+		wantResult matrixKvPairs
+		wantErr    error
+	}{
+		{
+			name: "transform",
+			opt: []OptionFunc{
+				SetTransform("**", trim),
+				SetTransform("users.phone", mask),
+				SetTransform("price", cents),
+				SetTransform("created", parseTime),
+				SetTransform("users.name", drop),
+			},
+			wantResult: matrixKvPairs{
+				[]pair{{Key: "created", Value: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)}, {Key: "name", Value: "cpu"}, {Key: "price", Value: 12.5}, {Key: "users.phone", Value: "***"}},
+			},
+		},
+		{
+			name:    "error",
+			opt:     []OptionFunc{SetTransform("name", parseTime)},
+			wantErr: errInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := newTestParser(append([]OptionFunc{SetExplode(ExplodeFirst, "users")}, tt.opt...)...)
+			gotResult, err := parser.ParseE(data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(covertHelper(gotResult), tt.wantResult) {
+				t.Errorf("ParseE() = %v, want %v", covertHelper(gotResult), tt.wantResult)
+			}
+		})
+	}
+}
+
+func Test_dataEtl_ParseTables_transform(t *testing.T) {
+	upper := func(path string, v interface{}) (interface{}, error) {
+		return strings.ToUpper(v.(string)), nil
+	}
+	parser := NewRelationalParser(SetLogger(NewStdLogger(LevelError, os.Stdout)), SetTransform("tags", upper))
+	tables, err := parser.ParseTables(map[string]interface{}{"tags": []string{"a"}})
+	if err != nil {
+		t.Fatalf("ParseTables() error = %v", err)
+	}
+	want := map[string][]map[string]interface{}{
+		RootTable: {{"_id": int64(1)}},
+		"tags":    {{"_id": int64(2), "_parent_id": int64(1), "_value": "A"}},
+	}
+	if !reflect.DeepEqual(tables, want) {
+		t.Errorf("ParseTables() = %v, want %v", tables, want)
+	}
+}
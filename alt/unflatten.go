@@ -0,0 +1,239 @@
+package alt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// flatField 扁平化之后的字段, path 为拆分之后的每一级字段名
+type flatField struct {
+	path  []string
+	value interface{}
+}
+
+type flatRow []flatField
+
+// Unflatten 将扁平化之后的多行数据还原为嵌套的文档, 是 Parse 的逆操作
+// sep 需要实现 PathSeparator, 用于将字段名拆分为每一级字段名
+// 在不同的行中取值不同的字段还原为列表, 对象列表的元素通过元素中基础类型的字段区分, 相同的行合并为一个元素
+// 只有一个元素的列表与对象展开之后没有区别, arrays 指定总是还原为列表的字段路径(格式见 pathSpec)
+// 对象列表与对象中基础类型的列表展开之后没有区别时返回 ErrAmbiguousList, 需要通过 arrays 指定对象列表的路径,
+// 或者指定对象中取值不同的字段为列表
+// 展开时会丢失重复的元素, 空列表, 空对象以及 nil, 这些值无法还原
+// 根节点为基础类型时字段名为空(例如 Parse(5)), 还原为 {"": 5}
+func Unflatten(rows []map[string]interface{}, sep Separator, arrays ...string) (map[string]interface{}, error) {
+	ps, ok := sep.(PathSeparator)
+	if !ok {
+		return nil, fmt.Errorf("unflatten: %T does not implement PathSeparator", sep)
+	}
+	var flat = make([]flatRow, 0, len(rows))
+	for _, row := range rows {
+		var keys = make([]string, 0, len(row))
+		for key := range row {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var fr = make(flatRow, 0, len(row))
+		for _, key := range keys {
+			path, err := ps.Split(key)
+			if err != nil {
+				return nil, err
+			}
+			if len(path) == 0 {
+				// JSONPointerSeparator 将空字段名拆分为空路径, 表示根节点
+				path = []string{""}
+			}
+			fr = append(fr, flatField{path: path, value: row[key]})
+		}
+		flat = append(flat, fr)
+	}
+	u := unflattener{arrays: parsePathSpecs(arrays)}
+	return u.object(flat, nil)
+}
+
+type unflattener struct {
+	arrays []pathSpec
+}
+
+// array 判断字段路径是否指定还原为列表
+func (u unflattener) array(path []string) bool {
+	for _, spec := range u.arrays {
+		if spec.match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// object 将多行数据还原为一个对象
+func (u unflattener) object(rows []flatRow, path []string) (map[string]interface{}, error) {
+	var (
+		keys []string
+		subs = make(map[string][]flatRow)
+	)
+	for _, row := range rows {
+		var sub = make(map[string]flatRow)
+		for _, f := range row {
+			k := f.path[0]
+			if _, ok := subs[k]; !ok {
+				keys = append(keys, k)
+				subs[k] = nil
+			}
+			sub[k] = append(sub[k], flatField{path: f.path[1:], value: f.value})
+		}
+		for k, fr := range sub {
+			subs[k] = append(subs[k], fr)
+		}
+	}
+	var obj = make(map[string]interface{}, len(keys))
+	for _, k := range keys {
+		v, err := u.value(subs[k], appendPath(path, k))
+		if err != nil {
+			return nil, err
+		}
+		obj[k] = v
+	}
+	return obj, nil
+}
+
+// value 将每一行中同一个字段之下的数据还原为基础类型, 对象或者列表
+func (u unflattener) value(rows []flatRow, path []string) (interface{}, error) {
+	var leaf, nested bool
+	for _, row := range rows {
+		for _, f := range row {
+			if len(f.path) == 0 {
+				leaf = true
+			} else {
+				nested = true
+			}
+		}
+	}
+	if leaf && nested {
+		return nil, fmt.Errorf("%w: %s is both a value and an object", ErrInvalidKey, formatPath(path))
+	}
+	if leaf {
+		var values []interface{}
+		groupRows(rows, func(row flatRow) []interface{} {
+			return []interface{}{row[0].value}
+		}, func(key []interface{}, _ []flatRow) {
+			values = append(values, key[0])
+		})
+		if len(values) == 1 && !u.array(path) {
+			return values[0], nil
+		}
+		return values, nil
+	}
+
+	// 通过对象中基础类型的字段区分列表的元素
+	var groups [][]flatRow
+	groupRows(rows, scalarKey, func(_ []interface{}, group []flatRow) {
+		groups = append(groups, group)
+	})
+	if !u.array(path) {
+		var list bool
+		if len(groups) > 1 {
+			var err error
+			if list, err = u.elements(rows, path, len(groups)); err != nil {
+				return nil, err
+			}
+		}
+		if !list {
+			return u.object(rows, path)
+		}
+	}
+	var values = make([]interface{}, 0, len(groups))
+	for _, group := range groups {
+		v, err := u.object(group, path)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// scalarKey 返回行中对象的基础类型字段, 用于区分列表的元素
+func scalarKey(row flatRow) (key []interface{}) {
+	for _, f := range row {
+		if len(f.path) == 1 {
+			key = append(key, f.path[0], f.value)
+		}
+	}
+	return key
+}
+
+// elements 判断基础类型字段取值不同的行是否为对象列表的不同元素, 而不是对象中基础类型的列表
+// 多个字段的取值相互关联(不是笛卡尔积)时只能是对象列表; 只有一个字段取值不同, 或者取值相互独立时两种方式都有可能,
+// 取值不同的字段都通过 arrays 指定为列表时还原为对象, 否则返回 ErrAmbiguousList
+func (u unflattener) elements(rows []flatRow, path []string, groups int) (bool, error) {
+	var (
+		names    []string
+		distinct = make(map[string]map[string]struct{})
+	)
+	for _, row := range rows {
+		for _, f := range row {
+			if len(f.path) == 1 {
+				if _, ok := distinct[f.path[0]]; !ok {
+					names = append(names, f.path[0])
+					distinct[f.path[0]] = make(map[string]struct{})
+				}
+			}
+		}
+	}
+	for _, row := range rows {
+		var values = make(map[string]string, len(names))
+		for _, f := range row {
+			if len(f.path) == 1 {
+				values[f.path[0]] = fmt.Sprintf("%T:%#v", f.value, f.value)
+			}
+		}
+		for _, name := range names {
+			// 缺少的字段也作为一个取值
+			distinct[name][values[name]] = struct{}{}
+		}
+	}
+	var varying []string
+	var product = 1
+	for _, name := range names {
+		if n := len(distinct[name]); n > 1 {
+			varying = append(varying, name)
+			product *= n
+		}
+	}
+	if len(varying) > 1 && product != groups {
+		return true, nil
+	}
+	for _, name := range varying {
+		if !u.array(appendPath(path, name)) {
+			return false, fmt.Errorf("%w: %s may be a list of objects or an object with list %s, specify one of them in arrays",
+				ErrAmbiguousList, formatPath(path), strings.Join(varying, ", "))
+		}
+	}
+	return false, nil
+}
+
+// groupRows 按 key 将行分组, 按照每组第一次出现的顺序调用 fn
+func groupRows(rows []flatRow, key func(row flatRow) []interface{}, fn func(key []interface{}, group []flatRow)) {
+	var (
+		order  []string
+		keys   = make(map[string][]interface{})
+		groups = make(map[string][]flatRow)
+	)
+	for _, row := range rows {
+		k := key(row)
+		var b strings.Builder
+		for _, v := range k {
+			fmt.Fprintf(&b, "%T:%#v;", v, v)
+		}
+		id := b.String()
+		if _, ok := groups[id]; !ok {
+			order = append(order, id)
+			keys[id] = k
+		}
+		groups[id] = append(groups[id], row)
+	}
+	for _, id := range order {
+		fn(keys[id], groups[id])
+	}
+}
@@ -0,0 +1,165 @@
+package alt
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestUnflatten(t *testing.T) {
+	// README 中的例子
+	var readme = map[string]interface{}{
+		"name": "map",
+		"data": []interface{}{
+			map[string]interface{}{"user_name": "小明", "age": 18, "province": "广东"},
+			map[string]interface{}{"user_name": "小海", "age": 17, "province": "海南"},
+		},
+		"data2": []interface{}{
+			map[string]interface{}{
+				"persons": []interface{}{
+					map[string]interface{}{"address": "广东"},
+					map[string]interface{}{"address": "海南"},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name   string
+		data   interface{}
+		sep    PathSeparator
+		arrays []string
+		want   interface{}
+	}{
+		{
+			name:   "readme",
+			data:   readme,
+			sep:    StrSeparator("."),
+			arrays: []string{"data2", "data2.persons"},
+			want:   readme,
+		},
+		{
+			name: "primitive_list",
+			data: map[string]interface{}{"id": 1, "tags": []interface{}{"a", "b"}, "meta": map[string]interface{}{"v": 1.5}},
+			sep:  StrSeparator("_"),
+			want: map[string]interface{}{"id": 1, "tags": []interface{}{"a", "b"}, "meta": map[string]interface{}{"v": 1.5}},
+		},
+		{
+			name:   "escape",
+			data:   map[string]interface{}{"a.b": 1, "a": map[string]interface{}{"b": 2, "c": []interface{}{3, 4}}},
+			sep:    EscapeSeparator("."),
+			arrays: []string{"a.c"},
+			want:   map[string]interface{}{"a.b": 1, "a": map[string]interface{}{"b": 2, "c": []interface{}{3, 4}}},
+		},
+		{
+			name:   "object_list",
+			data:   map[string]interface{}{"user": map[string]interface{}{"id": 1, "tags": []interface{}{"a", "b"}}, "point": map[string]interface{}{"x": []interface{}{1, 2}, "y": []interface{}{3, 4}}},
+			sep:    BracketSeparator{},
+			arrays: []string{"user.tags", "point.x", "point.y"},
+			want:   map[string]interface{}{"user": map[string]interface{}{"id": 1, "tags": []interface{}{"a", "b"}}, "point": map[string]interface{}{"x": []interface{}{1, 2}, "y": []interface{}{3, 4}}},
+		},
+		{
+			name: "correlated_list",
+			data: map[string]interface{}{"data": []interface{}{map[string]interface{}{"x": 1, "y": 2}, map[string]interface{}{"x": 2, "y": 3}}},
+			sep:  StrSeparator("."),
+			want: map[string]interface{}{"data": []interface{}{map[string]interface{}{"x": 1, "y": 2}, map[string]interface{}{"x": 2, "y": 3}}},
+		},
+		{
+			name: "nested_list",
+			data: map[string]interface{}{"orders": []interface{}{
+				map[string]interface{}{"id": 1, "items": []interface{}{map[string]interface{}{"sku": "x"}, map[string]interface{}{"sku": "y"}}},
+				map[string]interface{}{"id": 2, "items": []interface{}{map[string]interface{}{"sku": "x"}}},
+			}},
+			sep:    JSONPointerSeparator{},
+			arrays: []string{"orders", "**.items"},
+			want: map[string]interface{}{"orders": []interface{}{
+				map[string]interface{}{"id": 1, "items": []interface{}{map[string]interface{}{"sku": "x"}, map[string]interface{}{"sku": "y"}}},
+				map[string]interface{}{"id": 2, "items": []interface{}{map[string]interface{}{"sku": "x"}}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := newTestParser(SetSeparator(tt.sep))
+			rows, err := parser.ParseE(tt.data)
+			if err != nil {
+				t.Fatalf("ParseE() error = %v", err)
+			}
+			got, err := Unflatten(rows, tt.sep, tt.arrays...)
+			if err != nil {
+				t.Fatalf("Unflatten() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unflatten() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnflatten_root(t *testing.T) {
+	for _, sep := range []PathSeparator{StrSeparator("."), EscapeSeparator("."), BracketSeparator{}, JSONPointerSeparator{}} {
+		t.Run(fmt.Sprintf("%T", sep), func(t *testing.T) {
+			parser := newTestParser(SetSeparator(sep))
+			rows, err := parser.ParseE(5)
+			if err != nil {
+				t.Fatalf("ParseE() error = %v", err)
+			}
+			got, err := Unflatten(rows, sep)
+			if err != nil {
+				t.Fatalf("Unflatten() error = %v", err)
+			}
+			if want := map[string]interface{}{"": 5}; !reflect.DeepEqual(got, want) {
+				t.Errorf("Unflatten() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestUnflatten_error(t *testing.T) {
+	if _, err := Unflatten([]map[string]interface{}{{"a": 1}, {"a.b": 2}}, StrSeparator(".")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Unflatten() error = %v, want %v", err, ErrInvalidKey)
+	}
+	if _, err := Unflatten([]map[string]interface{}{{"a": 1}}, nil); err == nil {
+		t.Errorf("Unflatten() error = nil, want error")
+	}
+	if _, err := Unflatten([]map[string]interface{}{{"/a~": 1}}, JSONPointerSeparator{}); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Unflatten() error = %v, want %v", err, ErrInvalidKey)
+	}
+}
+
+func TestUnflatten_ambiguous(t *testing.T) {
+	// {"data":[{"x":1,"y":2},{"x":1,"y":3}]} 与 {"data":{"x":1,"y":[2,3]}} 展开之后相同
+	var rows = []map[string]interface{}{{"data.x": 1, "data.y": 2}, {"data.x": 1, "data.y": 3}}
+	tests := []struct {
+		name    string
+		arrays  []string
+		want    map[string]interface{}
+		wantErr error
+	}{
+		{
+			name:    "no_hint",
+			wantErr: ErrAmbiguousList,
+		},
+		{
+			name:   "object_list",
+			arrays: []string{"data"},
+			want:   map[string]interface{}{"data": []interface{}{map[string]interface{}{"x": 1, "y": 2}, map[string]interface{}{"x": 1, "y": 3}}},
+		},
+		{
+			name:   "primitive_list",
+			arrays: []string{"data.y"},
+			want:   map[string]interface{}{"data": map[string]interface{}{"x": 1, "y": []interface{}{2, 3}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Unflatten(rows, StrSeparator("."), tt.arrays...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Unflatten() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unflatten() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package writer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hotfizz/omega/alt"
+)
+
+// ErrNewColumn 写入表头之后出现了新的字段
+var ErrNewColumn = errors.New("new column")
+
+// QuoteStyle 字段值的引用方式
+type QuoteStyle int
+
+const (
+	// QuoteMinimal 只引用包含分隔符, 引号, 换行或者以空格开头的值, CSV 的默认值
+	QuoteMinimal QuoteStyle = iota
+	// QuoteAll 引用所有的值
+	QuoteAll
+	// QuoteNone 不使用引号, 使用 \ 转义分隔符, 换行和 \, 与 ClickHouse TabSeparated 格式一致, TSV 的默认值
+	QuoteNone
+)
+
+// NewColumnPolicy 写入表头之后出现新的字段时的处理方式
+type NewColumnPolicy int
+
+const (
+	// NewColumnError 返回 ErrNewColumn, 不写入该行(默认)
+	NewColumnError NewColumnPolicy = iota
+	// NewColumnDrop 丢弃新的字段
+	NewColumnDrop
+)
+
+type Option func(w *CSVWriter)
+
+// WithColumns 使用固定的字段及其顺序作为表头
+func WithColumns(columns ...string) Option {
+	return func(w *CSVWriter) {
+		w.setColumns(columns)
+	}
+}
+
+// WithSchema 使用 SchemaInferer 推断出的字段作为表头
+func WithSchema(schema *alt.Schema) Option {
+	return func(w *CSVWriter) {
+		var columns = make([]string, 0, len(schema.Columns))
+		for _, col := range schema.Columns {
+			columns = append(columns, col.Name)
+		}
+		w.setColumns(columns)
+	}
+}
+
+// WithBuffer 没有固定的字段时, 缓存前 n 行, 使用这些行中出现过的所有字段作为表头, 之后出现的新字段按照 NewColumnPolicy 处理
+// 默认 n <= 0, 缓存所有的行直到 Flush, 表头包含所有出现过的字段
+func WithBuffer(n int) Option {
+	return func(w *CSVWriter) {
+		w.bufferSize = n
+	}
+}
+
+// WithNewColumn 设置写入表头之后出现新的字段时的处理方式
+func WithNewColumn(policy NewColumnPolicy) Option {
+	return func(w *CSVWriter) {
+		w.newColumn = policy
+	}
+}
+
+// WithHeader 是否写入表头, 默认写入
+func WithHeader(header bool) Option {
+	return func(w *CSVWriter) {
+		w.header = header
+	}
+}
+
+// WithNull 设置 nil 以及不存在的字段的表示, CSV 默认为空字符串, TSV 默认为 \N
+func WithNull(null string) Option {
+	return func(w *CSVWriter) {
+		w.null = null
+	}
+}
+
+// WithQuote 设置字段值的引用方式
+func WithQuote(style QuoteStyle) Option {
+	return func(w *CSVWriter) {
+		w.quote = style
+	}
+}
+
+// WithDelimiter 设置分隔符
+func WithDelimiter(delimiter rune) Option {
+	return func(w *CSVWriter) {
+		w.delimiter = string(delimiter)
+	}
+}
+
+// WithFloatFormat 设置浮点数的格式, 参考 strconv.FormatFloat, 默认为 'f', -1
+func WithFloatFormat(format byte, prec int) Option {
+	return func(w *CSVWriter) {
+		w.floatFormat, w.floatPrec = format, prec
+	}
+}
+
+// WithBoolFormat 设置布尔值的表示, 默认为 true, false
+func WithBoolFormat(t, f string) Option {
+	return func(w *CSVWriter) {
+		w.boolTrue, w.boolFalse = t, f
+	}
+}
+
+// WithTimeFormat 设置时间的格式, 默认为 time.RFC3339Nano
+func WithTimeFormat(layout string) Option {
+	return func(w *CSVWriter) {
+		w.timeFormat = layout
+	}
+}
+
+// CSVWriter 将扁平化之后的行以 CSV 或者 TSV 的格式流式写入
+type CSVWriter struct {
+	w *bufio.Writer
+
+	columns    []string
+	index      map[string]int
+	fixed      bool
+	started    bool
+	buffer     []map[string]interface{}
+	bufferSize int
+	newColumn  NewColumnPolicy
+
+	header      bool
+	null        string
+	quote       QuoteStyle
+	delimiter   string
+	floatFormat byte
+	floatPrec   int
+	boolTrue    string
+	boolFalse   string
+	timeFormat  string
+	escaper     *strings.Replacer
+}
+
+// NewCSVWriter 创建 CSV 格式的 writer
+func NewCSVWriter(w io.Writer, opt ...Option) *CSVWriter {
+	return newWriter(w, ",", "", QuoteMinimal, opt)
+}
+
+// NewTSVWriter 创建 TSV 格式的 writer, 默认不使用引号, 使用 \ 转义特殊字符, nil 表示为 \N
+func NewTSVWriter(w io.Writer, opt ...Option) *CSVWriter {
+	return newWriter(w, "\t", `\N`, QuoteNone, opt)
+}
+
+func newWriter(w io.Writer, delimiter, null string, quote QuoteStyle, opt []Option) *CSVWriter {
+	cw := &CSVWriter{
+		w:           bufio.NewWriter(w),
+		index:       make(map[string]int),
+		header:      true,
+		null:        null,
+		quote:       quote,
+		delimiter:   delimiter,
+		floatFormat: 'f',
+		floatPrec:   -1,
+		boolTrue:    "true",
+		boolFalse:   "false",
+		timeFormat:  time.RFC3339Nano,
+	}
+	for _, f := range opt {
+		f(cw)
+	}
+	cw.escaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`, cw.delimiter, `\`+cw.delimiter)
+	return cw
+}
+
+func (w *CSVWriter) setColumns(columns []string) {
+	w.columns = nil
+	w.index = make(map[string]int, len(columns))
+	w.addColumns(columns)
+	w.fixed = true
+}
+
+func (w *CSVWriter) addColumns(columns []string) {
+	for _, name := range columns {
+		if _, ok := w.index[name]; !ok {
+			w.index[name] = len(w.columns)
+			w.columns = append(w.columns, name)
+		}
+	}
+}
+
+// Columns 返回当前的字段列表
+func (w *CSVWriter) Columns() []string {
+	return w.columns
+}
+
+// Write 写入一行, 没有固定的字段时, 在缓存了足够的行之后写入表头
+func (w *CSVWriter) Write(row map[string]interface{}) error {
+	if !w.started && !w.fixed {
+		w.buffer = append(w.buffer, row)
+		if w.bufferSize <= 0 || len(w.buffer) < w.bufferSize {
+			return nil
+		}
+		return w.start()
+	}
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+	return w.writeRow(row)
+}
+
+// WriteRows 依次写入多行
+func (w *CSVWriter) WriteRows(rows []map[string]interface{}) error {
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush 写入缓存的行并刷新到底层的 io.Writer
+func (w *CSVWriter) Flush() error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+	return w.w.Flush()
+}
+
+// start 确定表头, 写入表头以及缓存的行
+func (w *CSVWriter) start() error {
+	w.started = true
+	if !w.fixed {
+		w.addColumns(newColumns(w.buffer, nil))
+	}
+	if w.header && len(w.columns) > 0 {
+		var fields = make([]string, 0, len(w.columns))
+		for _, name := range w.columns {
+			fields = append(fields, w.quoteField(name))
+		}
+		if err := w.writeLine(fields); err != nil {
+			return err
+		}
+	}
+	buffer := w.buffer
+	w.buffer = nil
+	for _, row := range buffer {
+		if err := w.writeRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *CSVWriter) writeRow(row map[string]interface{}) error {
+	if added := newColumns([]map[string]interface{}{row}, w.index); len(added) > 0 && w.newColumn == NewColumnError {
+		return fmt.Errorf("%w: %s", ErrNewColumn, strings.Join(added, ", "))
+	}
+	var fields = make([]string, 0, len(w.columns))
+	for _, name := range w.columns {
+		v, ok := row[name]
+		if !ok || v == nil {
+			fields = append(fields, w.null)
+			continue
+		}
+		fields = append(fields, w.quoteField(w.format(v)))
+	}
+	return w.writeLine(fields)
+}
+
+func (w *CSVWriter) writeLine(fields []string) error {
+	if _, err := w.w.WriteString(strings.Join(fields, w.delimiter)); err != nil {
+		return err
+	}
+	return w.w.WriteByte('\n')
+}
+
+// newColumns 返回行中不在 known 中的字段, 按字段名排序
+func newColumns(rows []map[string]interface{}, known map[string]int) (columns []string) {
+	var seen = make(map[string]struct{})
+	for _, row := range rows {
+		for name := range row {
+			if _, ok := known[name]; ok {
+				continue
+			}
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				columns = append(columns, name)
+			}
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// format 将值格式化为字符串, 对象和列表序列化为 JSON
+func (w *CSVWriter) format(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case bool:
+		if x {
+			return w.boolTrue
+		}
+		return w.boolFalse
+	case float64:
+		return strconv.FormatFloat(x, w.floatFormat, w.floatPrec, 64)
+	case float32:
+		return strconv.FormatFloat(float64(x), w.floatFormat, w.floatPrec, 32)
+	case time.Time:
+		return x.Format(w.timeFormat)
+	case json.Number:
+		return x.String()
+	case json.RawMessage:
+		return string(x)
+	case []byte:
+		return string(x)
+	}
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		if b, err := json.Marshal(v); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// quoteField 按照 QuoteStyle 引用或者转义字段值
+func (w *CSVWriter) quoteField(s string) string {
+	switch w.quote {
+	case QuoteAll:
+		return `"` + strings.Replace(s, `"`, `""`, -1) + `"`
+	case QuoteNone:
+		return w.escaper.Replace(s)
+	}
+	// 与 null 相同的值需要引用, 避免与 nil 混淆
+	if s != w.null && (s == "" || (!strings.ContainsAny(s, "\"\r\n") && !strings.Contains(s, w.delimiter) && s[0] != ' ' && s[0] != '\t')) {
+		return s
+	}
+	return `"` + strings.Replace(s, `"`, `""`, -1) + `"`
+}
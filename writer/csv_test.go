@@ -0,0 +1,143 @@
+package writer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hotfizz/omega/alt"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var rows = []map[string]interface{}{
+		{"name": "map", "age": 18, "score": 0.5},
+		{"name": "a,\"b\"", "ok": true, "at": time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"name": "", "tags": []interface{}{"x", 1}, "score": json.Number("1e3")},
+	}
+	tests := []struct {
+		name    string
+		tsv     bool
+		opt     []Option
+		want    string
+		wantErr error
+	}{
+		{
+			// 默认缓存所有的行, 表头包含所有出现过的字段
+			name: "default",
+			want: "age,at,name,ok,score,tags\n" +
+				"18,,map,,0.5,\n" +
+				",2021-01-02T03:04:05Z,\"a,\"\"b\"\"\",true,,\n" +
+				",,\"\",,1e3,\"[\"\"x\"\",1]\"\n",
+		},
+		{
+			name: "buffer",
+			opt:  []Option{WithBuffer(2), WithNewColumn(NewColumnDrop), WithNull("NULL"), WithBoolFormat("1", "0"), WithTimeFormat("2006-01-02")},
+			want: "age,at,name,ok,score\n" +
+				"18,NULL,map,NULL,0.5\n" +
+				"NULL,2021-01-02,\"a,\"\"b\"\"\",1,NULL\n" +
+				"NULL,NULL,,NULL,1e3\n",
+		},
+		{
+			name:    "buffer_error",
+			opt:     []Option{WithBuffer(1)},
+			want:    "age,name,score\n18,map,0.5\n",
+			wantErr: ErrNewColumn,
+		},
+		{
+			name: "drop",
+			opt:  []Option{WithColumns("name", "score"), WithNewColumn(NewColumnDrop), WithQuote(QuoteAll), WithFloatFormat('f', 2)},
+			want: "\"name\",\"score\"\n" +
+				"\"map\",\"0.50\"\n" +
+				"\"a,\"\"b\"\"\",\n" +
+				"\"\",\"1e3\"\n",
+		},
+		{
+			name:    "error",
+			opt:     []Option{WithColumns("name", "score", "age")},
+			want:    "name,score,age\nmap,0.5,18\n",
+			wantErr: ErrNewColumn,
+		},
+		{
+			name: "schema",
+			opt:  []Option{WithSchema(&alt.Schema{Columns: []alt.ColumnSchema{{Name: "score"}, {Name: "name"}}}), WithNewColumn(NewColumnDrop), WithHeader(false)},
+			want: "0.5,map\n" +
+				",\"a,\"\"b\"\"\"\n" +
+				"1e3,\"\"\n",
+		},
+		{
+			name: "tsv",
+			tsv:  true,
+			opt:  []Option{WithColumns("name", "ok", "tags"), WithNewColumn(NewColumnDrop)},
+			want: "name\tok\ttags\n" +
+				"map\t\\N\t\\N\n" +
+				"a,\"b\"\ttrue\t\\N\n" +
+				"\t\\N\t[\"x\",1]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			var w *CSVWriter
+			if tt.tsv {
+				w = NewTSVWriter(&buf, tt.opt...)
+			} else {
+				w = NewCSVWriter(&buf, tt.opt...)
+			}
+			err := w.WriteRows(rows)
+			if err == nil {
+				err = w.Flush()
+			} else {
+				_ = w.Flush()
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WriteRows() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriteRows() = \n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVWriter_escape(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewTSVWriter(&buf, WithColumns("v"))
+	if err := w.WriteRows([]map[string]interface{}{{"v": "a\tb\nc\\d"}, {"v": `\N`}}); err != nil {
+		t.Fatalf("WriteRows() error = %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got, want := buf.String(), "v\na\\tb\\nc\\\\d\n\\\\N\n"; got != want {
+		t.Errorf("WriteRows() = %q, want %q", got, want)
+	}
+}
+
+func TestCSVWriter_parse(t *testing.T) {
+	// Parse 输出的行中字段不一定相同, 默认的设置需要能够写入
+	var doc = map[string]interface{}{
+		"name": "map",
+		"data": []interface{}{
+			map[string]interface{}{"user_name": "小明", "age": 18},
+			map[string]interface{}{"user_name": "小海", "province": "海南"},
+		},
+	}
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf)
+	parser := alt.NewDataEtlParser(alt.SetLogger(alt.NewStdLogger(alt.LevelError, os.Stdout)), alt.SetDeterministic(true))
+	if err := parser.ParseEach(doc, w.Write); err != nil {
+		t.Fatalf("ParseEach() error = %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	want := "data.age,data.province,data.user_name,name\n" +
+		"18,,小明,map\n" +
+		",海南,小海,map\n"
+	if got := buf.String(); got != want {
+		t.Errorf("ParseEach() = \n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,44 @@
+package writer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/hotfizz/omega/alt"
+)
+
+// JSONLinesWriter 将扁平化之后的行写为 JSON Lines, 每行一个 JSON 对象, 字段按字段名排序
+type JSONLinesWriter struct {
+	w      *bufio.Writer
+	buf    bytes.Buffer
+	enc    *json.Encoder
+	rows   int
+	offset int64
+}
+
+func NewJSONLinesWriter(w io.Writer) *JSONLinesWriter {
+	jw := &JSONLinesWriter{w: bufio.NewWriter(w)}
+	jw.enc = json.NewEncoder(&jw.buf)
+	jw.enc.SetEscapeHTML(false)
+	return jw
+}
+
+// Write 写入一行, 无法序列化的行返回 *alt.LineError, Line 为该行是第几次写入, Offset 为当前输出的偏移量
+// 返回 *alt.LineError 时不会写入任何内容, 可以继续写入之后的行
+func (w *JSONLinesWriter) Write(row map[string]interface{}) error {
+	w.rows++
+	w.buf.Reset()
+	if err := w.enc.Encode(row); err != nil {
+		return &alt.LineError{Line: w.rows, Offset: w.offset, Err: err}
+	}
+	n, err := w.w.Write(w.buf.Bytes())
+	w.offset += int64(n)
+	return err
+}
+
+// Flush 刷新到底层的 io.Writer
+func (w *JSONLinesWriter) Flush() error {
+	return w.w.Flush()
+}
@@ -0,0 +1,37 @@
+package writer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/hotfizz/omega/alt"
+)
+
+func TestJSONLinesWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONLinesWriter(&buf)
+	rows := []map[string]interface{}{
+		{"name": "<map>", "data.age": json.Number("18")},
+		{"score": math.NaN()},
+		{"name": "小明"},
+	}
+	var errs []error
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got, want := buf.String(), "{\"data.age\":18,\"name\":\"<map>\"}\n{\"name\":\"小明\"}\n"; got != want {
+		t.Errorf("Write() = %q, want %q", got, want)
+	}
+	var lineErr *alt.LineError
+	if len(errs) != 1 || !errors.As(errs[0], &lineErr) || lineErr.Line != 2 || lineErr.Offset != 31 {
+		t.Errorf("Write() errors = %v, want one error at row 2, offset 31", errs)
+	}
+}